@@ -0,0 +1,127 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/serverless/platform"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/journal"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+const (
+	Use = "history"
+
+	TargetFolderFlag = "target"
+	OutputFlag       = "output"
+	LimitFlag        = "limit"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                Use,
+		RunE:               run,
+		FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+		Version:            version.Version,
+		Short:              "Print the history of the OneAgent deployments in the target folder",
+	}
+
+	addFlags(cmd)
+
+	return cmd
+}
+
+var (
+	targetFolder string
+	output       string
+	limit        int
+)
+
+func addFlags(cmd *cobra.Command) {
+	// the same default as for the serverless command, so the history of its deployments can be printed without any flags
+	defaultTarget := platform.Detect(platform.OSEnv(), platform.Providers...).DefaultPaths().Target
+
+	cmd.Flags().StringVar(&targetFolder, TargetFolderFlag, defaultTarget, "Base path where the CodeModule is deployed to. Only required if the platform has no default.")
+
+	if defaultTarget == "" {
+		err := cmd.MarkFlagRequired(TargetFolderFlag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	cmd.Flags().StringVar(&output, OutputFlag, OutputText, "(Optional) Output format, either 'text' or 'json'.")
+	cmd.Flags().IntVar(&limit, LimitFlag, 0, "(Optional) Only print the given number of the most recent deployments. Prints all deployments if not set.")
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	if output != OutputText && output != OutputJSON {
+		return fmt.Errorf("invalid output format %q, must be %q or %q", output, OutputText, OutputJSON)
+	}
+
+	records, err := deployment.NewHistory(targetFolder).Read()
+	if err != nil {
+		return err
+	}
+
+	if limit > 0 && len(records) > limit {
+		records = records[len(records)-limit:]
+	}
+
+	if output == OutputJSON {
+		return printJSON(cmd.OutOrStdout(), records)
+	}
+
+	return printText(cmd.OutOrStdout(), records)
+}
+
+func printJSON(w io.Writer, records []journal.Record) error {
+	if records == nil {
+		records = []journal.Record{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(records)
+}
+
+func printText(w io.Writer, records []journal.Record) error {
+	const padding = 2
+
+	tw := tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "TIMESTAMP\tINSTANCE\tVERSION\tTECHNOLOGIES\tDURATION\tOUTCOME\tPREVIOUS ACTIVE\tERROR")
+
+	for _, record := range records {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			record.Timestamp.Format("2006-01-02T15:04:05Z07:00"),
+			record.Instance,
+			record.Version,
+			valueOr(record.Technologies, "all"),
+			record.Duration,
+			record.Outcome,
+			valueOr(record.PreviousActive, "-"),
+			valueOr(record.Error, "-"),
+		)
+	}
+
+	return tw.Flush()
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryCmd(t *testing.T) {
+	t.Run("missing 'target' parameter results in an error", func(t *testing.T) {
+		_, err := execute(t)
+		require.ErrorContains(t, err, "required flag(s) \"target\" not set")
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		out, err := execute(t, "--target", t.TempDir(), "--output", "json")
+		require.NoError(t, err)
+		assert.JSONEq(t, "[]", out)
+	})
+
+	t.Run("text output", func(t *testing.T) {
+		targetDir := t.TempDir()
+		setupHistory(t, targetDir, "1.325.22.20251002-101422", "1.327.30.20251107-111521")
+
+		out, err := execute(t, "--target", targetDir)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[0], "TIMESTAMP"))
+		assert.Contains(t, lines[1], "1.325.22.20251002-101422")
+		assert.Contains(t, lines[1], "success")
+		assert.Contains(t, lines[2], "1.327.30.20251107-111521")
+	})
+
+	t.Run("limit to the most recent deployments", func(t *testing.T) {
+		targetDir := t.TempDir()
+		setupHistory(t, targetDir, "1.325.22.20251002-101422", "1.327.30.20251107-111521")
+
+		out, err := execute(t, "--target", targetDir, "--output", "json", "--limit", "1")
+		require.NoError(t, err)
+
+		var records []journal.Record
+		require.NoError(t, json.Unmarshal([]byte(out), &records))
+		require.Len(t, records, 1)
+		assert.Equal(t, "1.327.30.20251107-111521", records[0].Version)
+	})
+
+	t.Run("invalid output format results in an error", func(t *testing.T) {
+		_, err := execute(t, "--target", t.TempDir(), "--output", "yaml")
+		require.ErrorContains(t, err, "invalid output format")
+	})
+}
+
+func setupHistory(t *testing.T, targetDir string, versions ...string) {
+	t.Helper()
+
+	history := deployment.NewHistory(targetDir)
+
+	for _, version := range versions {
+		require.NoError(t, history.Append(journal.Record{
+			Timestamp: time.Now().UTC(),
+			Instance:  "instance-1",
+			Version:   version,
+			Duration:  "1s",
+			Outcome:   journal.OutcomeSuccess,
+		}))
+	}
+}
+
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	cmd := New()
+	cmd.SetArgs(args)
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+
+	return out.String(), err
+}
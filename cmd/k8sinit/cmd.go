@@ -2,9 +2,12 @@ package k8sinit
 
 import (
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/configure"
 	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/move"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/flags"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/version"
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -26,6 +29,7 @@ const (
 func New() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                Use,
+		PreRunE:            PreRunE,
 		RunE:               RunE,
 		FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
 		Version:            version.Version,
@@ -66,18 +70,31 @@ func AddFlags(cmd *cobra.Command) {
 
 	move.AddFlags(cmd)
 	configure.AddFlags(cmd)
+
+	flags.AddConfigFileFlag(cmd)
+}
+
+// PreRunE applies the environment variables and the config file, so its values are taken into account when validating the required flags.
+func PreRunE(cmd *cobra.Command, _ []string) error {
+	return flags.Apply(cmd)
 }
 
-func RunE(_ *cobra.Command, _ []string) error {
+func RunE(cmd *cobra.Command, _ []string) error {
 	setupLogger()
 
+	// abort the copy on SIGINT/SIGTERM, so the partial copy in the work folder is cleaned up before exiting
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	if isDebug {
 		log.Info("debug logs enabled")
 	}
 
+	flags.LogSources(log, cmd)
+
 	version.Print(log)
 
-	err := move.Execute(log, sourceFolder, targetFolder)
+	err := move.Execute(ctx, log, sourceFolder, targetFolder)
 	if err != nil {
 		if areErrorsSuppressed {
 			log.Error(err, "error during moving, the error was suppressed")
@@ -90,30 +107,57 @@ func RunE(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	err = configure.SetupOneAgent(log, targetFolder)
-	if err != nil {
+	fingerprint, isConfigured := configure.CheckFingerprint(log, targetFolder, enableAttributesDTKubernetes)
+	if isConfigured {
+		log.Info("none of the inputs changed since the last successful configuration, skipping the configuration and enrichment", "fingerprint", fingerprint)
+
+		return nil
+	}
+
+	// with --continue-on-error, the errors of the containers are reported by the setup and enrichment,
+	// so the enrichment still runs for every container, and all errors are reported at once
+	setupErr := configure.SetupOneAgent(log, targetFolder)
+	if setupErr != nil && !configure.IsReport(setupErr) {
 		if areErrorsSuppressed {
-			log.Error(err, "error during oneagent setup, the error was suppressed")
+			log.Error(setupErr, "error during oneagent setup, the error was suppressed")
 
 			return nil
 		}
 
-		log.Error(err, "error during configuration")
+		log.Error(setupErr, "error during configuration")
 
-		return err
+		return setupErr
 	}
 
-	err = configure.EnrichWithMetadata(log, enableAttributesDTKubernetes)
-	if err != nil {
+	enrichErr := configure.EnrichWithMetadata(log, enableAttributesDTKubernetes)
+	if enrichErr != nil && !configure.IsReport(enrichErr) {
 		if areErrorsSuppressed {
-			log.Error(err, "error during enrichment, the error was suppressed")
+			log.Error(enrichErr, "error during enrichment, the error was suppressed")
 
 			return nil
 		}
 
-		log.Error(err, "error during enrichment")
+		log.Error(enrichErr, "error during enrichment")
 
-		return err
+		return enrichErr
+	}
+
+	if report := configure.JoinReports(setupErr, enrichErr); report != nil {
+		if areErrorsSuppressed {
+			log.Error(report, "error during configuration of some containers, the error was suppressed", "failed containers", report.Containers(), "errors", report.ByContainer())
+
+			return nil
+		}
+
+		log.Error(report, "error during configuration of some containers", "failed containers", report.Containers(), "errors", report.ByContainer())
+
+		return report
+	}
+
+	err = configure.StoreFingerprint(log, fingerprint)
+	if err != nil {
+		// the next run configures the containers again, which is the behavior without a fingerprint
+		log.Error(err, "failed to store the fingerprint of the inputs")
 	}
 
 	return nil
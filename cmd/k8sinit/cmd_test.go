@@ -5,7 +5,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/configure"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/fingerprint"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/move"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/flags"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
 	"github.com/stretchr/testify/require"
 )
 
@@ -134,6 +138,133 @@ func TestEnableAttributesDTKubernetes(t *testing.T) {
 	})
 }
 
+func TestFingerprint(t *testing.T) {
+	const containerName = "test-container"
+
+	srcDir := t.TempDir()
+	cfgDir := t.TempDir()
+	inputDir := t.TempDir()
+	setupSource(t, srcDir)
+
+	metadataFile := filepath.Join(cfgDir, containerName, "enrichment", "dt_metadata.json")
+
+	execute := func(t *testing.T, args ...string) {
+		t.Helper()
+
+		cmd := New()
+		cmd.SetArgs(append([]string{
+			"--source", srcDir,
+			"--target", t.TempDir(),
+			"--config-directory", cfgDir,
+			"--input-directory", inputDir,
+			"--attribute=k8s.cluster.uid=test-cluster-uid",
+			`--attribute-container={"k8s.container.name": "` + containerName + `"}`,
+		}, args...))
+
+		require.NoError(t, cmd.Execute())
+	}
+
+	tamper := func(t *testing.T) {
+		t.Helper()
+
+		require.NoError(t, os.WriteFile(metadataFile, []byte("tampered"), 0600))
+	}
+
+	execute(t)
+	require.FileExists(t, filepath.Join(cfgDir, fingerprint.FileName))
+
+	t.Run("unchanged inputs skip the configuration", func(t *testing.T) {
+		tamper(t)
+		execute(t)
+
+		content, err := os.ReadFile(metadataFile)
+		require.NoError(t, err)
+		require.Equal(t, "tampered", string(content))
+	})
+
+	t.Run("changed input file configures again", func(t *testing.T) {
+		tamper(t)
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, "new-input"), []byte("content"), 0600))
+		execute(t)
+
+		content, err := os.ReadFile(metadataFile)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "test-cluster-uid")
+	})
+
+	t.Run("changed flag configures again", func(t *testing.T) {
+		tamper(t)
+		execute(t, "--"+EnableAttributesDTKubernetesFlag+"=false")
+
+		content, err := os.ReadFile(metadataFile)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "test-cluster-uid")
+	})
+
+	t.Run("--force configures again", func(t *testing.T) {
+		tamper(t)
+		execute(t, "--"+EnableAttributesDTKubernetesFlag+"=false", "--force")
+
+		content, err := os.ReadFile(metadataFile)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "test-cluster-uid")
+	})
+
+	t.Run("changed system CA file configures again", func(t *testing.T) {
+		systemCAFile := filepath.Join(t.TempDir(), "ca-certificates.crt")
+		args := []string{"--" + EnableAttributesDTKubernetesFlag + "=false", "--" + configure.SystemCAFileFlag, systemCAFile}
+
+		require.NoError(t, os.WriteFile(systemCAFile, []byte(tests.NewValidCertificatePEM(t, "system")), 0600))
+		execute(t, args...)
+
+		tamper(t)
+		require.NoError(t, os.WriteFile(systemCAFile, []byte(tests.NewValidCertificatePEM(t, "updated")), 0600))
+		execute(t, args...)
+
+		content, err := os.ReadFile(metadataFile)
+		require.NoError(t, err)
+		require.Contains(t, string(content), "test-cluster-uid")
+	})
+}
+
+func TestConfigFile(t *testing.T) {
+	t.Run("all options can be set in the config file", func(t *testing.T) {
+		const containerName = "test-container"
+
+		srcDir := t.TempDir()
+		cfgDir := t.TempDir()
+		setupSource(t, srcDir)
+
+		configFile := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configFile, []byte(`
+source: `+srcDir+`
+target: `+t.TempDir()+`
+config-directory: `+cfgDir+`
+input-directory: `+t.TempDir()+`
+enable-attributes-dt-kubernetes: false
+attribute:
+  - k8s.cluster.uid=test-cluster-uid
+  - k8s.workload.kind=Deployment
+attribute-container:
+  - k8s.container.name: `+containerName+`
+`), 0600))
+
+		cmd := New()
+		cmd.SetArgs([]string{"--config", configFile})
+
+		require.NoError(t, cmd.Execute())
+
+		jsonContent, err := os.ReadFile(filepath.Join(cfgDir, containerName, "enrichment", "dt_metadata.json"))
+		require.NoError(t, err)
+		require.Contains(t, string(jsonContent), "test-cluster-uid")
+		require.NotContains(t, string(jsonContent), "dt.kubernetes.cluster.id")
+	})
+
+	t.Run("the published schema is up to date", func(t *testing.T) {
+		tests.RequireGoldenJSON(t, "../../schema/k8s-init.schema.json", flags.Schema("dynatrace-bootstrapper k8s-init config file", New().Flags()))
+	})
+}
+
 func setupSource(t *testing.T, folder string) {
 	t.Helper()
 
@@ -1,36 +1,58 @@
 package configure
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/configure/attributes/container"
 	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/configure/attributes/pod"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/enrichment/endpoint"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/enrichment/metadata"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/fingerprint"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/ca"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/conf"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/curl"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pgc"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/preload"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/move"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/flags"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/version"
 	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
 )
 
 const (
-	InputFolderFlag  = "input-directory"
-	ConfigFolderFlag = "config-directory"
-	InstallPathFlag  = "install-path"
-	IsFullstackFlag  = "fullstack"
-	TenantFlag       = "tenant"
+	InputFolderFlag     = "input-directory"
+	ConfigFolderFlag    = "config-directory"
+	InstallPathFlag     = "install-path"
+	IsFullstackFlag     = "fullstack"
+	TenantFlag          = "tenant"
+	ForceFlag           = "force"
+	ContinueOnErrorFlag = "continue-on-error"
+	ConcurrencyFlag     = "concurrency"
+	SystemCAFileFlag    = "system-ca-file"
+
+	defaultConcurrency = 4
 )
 
 var (
-	inputDir    string
-	configDir   string
-	installPath string
-	isFullstack bool
-	tenant      string
+	inputDir        string
+	configDir       string
+	installPath     string
+	isFullstack     bool
+	tenant          string
+	force           bool
+	continueOnError bool
+	concurrency     int
+	systemCAFile    string
 
 	podAttributes       []string
 	containerAttributes []string
@@ -48,100 +70,202 @@ func AddFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolVar(&isFullstack, IsFullstackFlag, false, "(Optional) Configure the CodeModule to be fullstack.")
 	cmd.Flags().StringVar(&tenant, TenantFlag, "", "The name of the tenant that the CodeModule will communicate with. Mandatory in case of --fullstack.")
 
+	cmd.Flags().StringVar(&systemCAFile, SystemCAFileFlag, "", "(Optional) Path to a CA bundle of the system (e.g., /etc/ssl/certs/ca-certificates.crt), whose certificates are merged into the certificates trusted by the CodeModule.")
+
 	cmd.Flags().Lookup(IsFullstackFlag).NoOptDefVal = "true"
+
+	cmd.Flags().BoolVar(&force, ForceFlag, false, "(Optional) Configure the containers even if none of the inputs changed since the last successful configuration.")
+
+	cmd.Flags().Lookup(ForceFlag).NoOptDefVal = "true"
+
+	cmd.Flags().BoolVar(&continueOnError, ContinueOnErrorFlag, false, "(Optional) Configure and enrich every container, even if some of them fail, and report the errors of all containers at once.")
+
+	cmd.Flags().Lookup(ContinueOnErrorFlag).NoOptDefVal = "true"
+
+	cmd.Flags().IntVar(&concurrency, ConcurrencyFlag, defaultConcurrency, "(Optional) Maximum number of containers which are configured at the same time.")
+
+	// the container attributes can be given as objects in the config file
+	_ = cmd.Flags().SetAnnotation(container.Flag, flags.JSONAnnotation, []string{"true"})
 }
 
-func SetupOneAgent(log logr.Logger, targetDir string) error {
+// CheckFingerprint computes the fingerprint of all inputs of the configuration and the enrichment (the files in the input directory, the attributes, the flags and the OneAgent version),
+// and reports whether it matches the fingerprint stored by the last successful run, in which case both can be skipped.
+// The returned fingerprint is empty if there is nothing to configure, or it could not be computed.
+func CheckFingerprint(log logr.Logger, targetDir string, withDeprecatedAttributes bool) (string, bool) {
 	if configDir == "" || inputDir == "" {
-		return nil
+		return "", false
 	}
 
-	log.Info("starting configuration", "config-directory", configDir, "input-directory", inputDir)
-
-	err := preload.Configure(log, configDir, installPath)
+	current, err := fingerprint.Compute(inputDir, getFingerprintValues(targetDir, withDeprecatedAttributes)...)
 	if err != nil {
-		log.Info("failed to configure the ld.so.preload", "config-directory", configDir)
+		log.Info("failed to compute the fingerprint of the inputs, the containers will be configured", "error", err.Error())
 
-		return err
+		return "", false
 	}
 
-	podAttr, err := pod.ParseAttributes(podAttributes)
-	if err != nil {
-		return err
+	if force {
+		log.Info("configuration is forced, the containers will be configured", "fingerprint", current)
+
+		return current, false
 	}
 
-	containerAttrs, err := container.ParseAttributes(containerAttributes)
+	stored, err := fingerprint.Read(configDir)
 	if err != nil {
-		return err
+		log.Info("failed to read the stored fingerprint, the containers will be configured", "error", err.Error())
+
+		return current, false
 	}
 
-	for _, containerAttr := range containerAttrs {
-		containerConfigDir := filepath.Join(configDir, containerAttr.ContainerName)
+	return current, stored == current
+}
 
-		err = configureContainer(log, inputDir, targetDir, containerConfigDir, containerAttr, podAttr, tenant, isFullstack)
-		if err != nil {
-			return err
-		}
+// StoreFingerprint stores the fingerprint of the inputs in the config directory, it must be called after a successful configuration and enrichment.
+func StoreFingerprint(log logr.Logger, current string) error {
+	if current == "" {
+		return nil
 	}
 
-	log.Info("finished oneagent configuration", "config-directory", configDir, "input-directory", inputDir)
+	log.Info("storing the fingerprint of the inputs", "config-directory", configDir, "fingerprint", current)
 
-	return nil
+	return fingerprint.Write(configDir, current)
 }
 
-func configureContainer(log logr.Logger, inputDir, targetDir, containerConfigDir string, containerAttr container.Attributes, podAttr pod.Attributes, tenant string, isFullstack bool) error {
-	log.Info("starting to configure the container", "path", containerConfigDir)
+func getFingerprintValues(targetDir string, withDeprecatedAttributes bool) []string {
+	// a missing version file just results in a different fingerprint, the configuration reports the actual problem
+	agentVersion, _ := os.ReadFile(filepath.Join(targetDir, move.InstallerVersionFilePath))
+
+	values := []string{
+		"bootstrapper-version=" + version.Version,
+		"agent-version=" + strings.TrimSpace(string(agentVersion)),
+		InstallPathFlag + "=" + installPath,
+		IsFullstackFlag + "=" + strconv.FormatBool(isFullstack),
+		TenantFlag + "=" + tenant,
+		"enable-attributes-dt-kubernetes=" + strconv.FormatBool(withDeprecatedAttributes),
+		SystemCAFileFlag + "=" + systemCAFile,
+	}
 
-	err := pmc.Configure(log, inputDir, targetDir, containerConfigDir, installPath)
-	if err != nil {
-		log.Info("failed to configure the ruxitagentproc.conf", "config-directory", containerConfigDir)
+	if systemCAFile != "" {
+		// the system CA file is not in the input directory, so its content is part of the values, a missing file is reported by the configuration
+		systemCA, _ := os.ReadFile(systemCAFile)
+		hash := sha256.Sum256(systemCA)
 
-		return err
+		values = append(values, "system-ca-sha256="+hex.EncodeToString(hash[:]))
 	}
 
-	err = conf.Configure(log, containerConfigDir, containerAttr, podAttr, tenant, isFullstack)
-	if err != nil {
-		log.Info("failed to configure the container-conf files", "config-directory", containerConfigDir)
+	for _, attr := range podAttributes {
+		values = append(values, pod.Flag+"="+attr)
+	}
 
-		return err
+	for _, attr := range containerAttributes {
+		values = append(values, container.Flag+"="+attr)
 	}
 
-	err = configureFromInputDir(log, containerConfigDir, inputDir)
-	if err != nil {
-		log.Info("failed to configure container", "config-directory", containerConfigDir)
+	return values
+}
 
-		return err
+func SetupOneAgent(log logr.Logger, targetDir string) error {
+	if configDir == "" || inputDir == "" {
+		return nil
 	}
 
+	log.Info("starting configuration", "config-directory", configDir, "input-directory", inputDir)
 
-	return nil
-}
-
-func configureFromInputDir(log logr.Logger, containerConfigDir, inputDir string) error {
-	err := curl.Configure(log, inputDir, containerConfigDir)
+	err := preload.Configure(log, configDir, installPath)
 	if err != nil {
-		log.Info("failed to configure the curl options", "config-directory", containerConfigDir)
+		log.Info("failed to configure the ld.so.preload", "config-directory", configDir)
 
 		return err
 	}
 
-	err = ca.Configure(log, inputDir, containerConfigDir)
+	podAttr, err := pod.ParseAttributes(podAttributes)
 	if err != nil {
-		log.Info("failed to configure the CAs", "config-directory", containerConfigDir)
+		return err
+	}
 
+	containerAttrs, err := container.ParseAttributes(containerAttributes)
+	if err != nil {
 		return err
 	}
-	
-	err = pgc.Configure(log, inputDir, containerConfigDir)
+
+	var inputs podInputs
+
+	inputs.pmcConf, inputs.pmcErr = pmc.Parse(log, inputDir, targetDir, installPath)
+	inputs.caBundles, inputs.caErr = ca.Parse(log, inputDir, systemCAFile)
+
+	err = forEachContainer(log, containerAttrs, func(log logr.Logger, containerAttr container.Attributes) []*ContainerError {
+		containerConfigDir := filepath.Join(configDir, containerAttr.ContainerName)
+
+		return configureContainer(log, inputs, inputDir, containerConfigDir, containerAttr, podAttr, tenant, isFullstack)
+	})
 	if err != nil {
-		log.Info("failed to configure declarative.cbor", "config-directory", containerConfigDir)
+		var report *Report
+		if errors.As(err, &report) {
+			log.Info("finished oneagent configuration with errors", "config-directory", configDir, "failed containers", report.Containers())
+		}
 
 		return err
 	}
 
+	log.Info("finished oneagent configuration", "config-directory", configDir, "input-directory", inputDir)
+
 	return nil
 }
 
+// podInputs are the inputs which are the same for every container of the pod, so they are only parsed once.
+// A parsing error is reported for each container.
+type podInputs struct {
+	pmcErr    error
+	caErr     error
+	pmcConf   pmc.Conf
+	caBundles ca.Bundles
+}
+
+func configureContainer(log logr.Logger, inputs podInputs, inputDir, containerConfigDir string, containerAttr container.Attributes, podAttr pod.Attributes, tenant string, isFullstack bool) []*ContainerError {
+	log.Info("starting to configure the container", "path", containerConfigDir)
+
+	return runSteps(log, containerAttr.ContainerName, containerConfigDir, []step{
+		{
+			name:       StepPMC,
+			failureMsg: "failed to configure the ruxitagentproc.conf",
+			run: func() error {
+				if inputs.pmcErr != nil {
+					return inputs.pmcErr
+				}
+
+				return inputs.pmcConf.Write(log, containerConfigDir)
+			},
+		},
+		{
+			name:       StepConf,
+			failureMsg: "failed to configure the container-conf files",
+			run: func() error {
+				return conf.Configure(log, containerConfigDir, containerAttr, podAttr, tenant, isFullstack)
+			},
+		},
+		{
+			name:       StepCurl,
+			failureMsg: "failed to configure the curl options",
+			run:        func() error { return curl.Configure(log, inputDir, containerConfigDir) },
+		},
+		{
+			name:       StepCA,
+			failureMsg: "failed to configure the CAs",
+			run: func() error {
+				if inputs.caErr != nil {
+					return inputs.caErr
+				}
+
+				return inputs.caBundles.Write(log, containerConfigDir)
+			},
+		},
+		{
+			name:       StepPGC,
+			failureMsg: "failed to configure declarative.cbor",
+			run:        func() error { return pgc.Configure(log, inputDir, containerConfigDir) },
+		},
+	})
+}
+
 func EnrichWithMetadata(log logr.Logger, withDeprecatedAttributes bool) error {
 	if configDir == "" || inputDir == "" {
 		return nil
@@ -159,26 +283,112 @@ func EnrichWithMetadata(log logr.Logger, withDeprecatedAttributes bool) error {
 		return err
 	}
 
-	for _, containerAttr := range containerAttrs {
+	err = forEachContainer(log, containerAttrs, func(log logr.Logger, containerAttr container.Attributes) []*ContainerError {
 		containerConfigDir := filepath.Join(configDir, containerAttr.ContainerName)
 		log.Info("starting to enrich the container", "path", containerConfigDir)
 
-		err = endpoint.Configure(log, inputDir, containerConfigDir)
-		if err != nil {
-			log.Info("failed to configure the endpoint.properties", "config-directory", configDir)
+		return runSteps(log, containerAttr.ContainerName, containerConfigDir, []step{
+			{
+				name:       StepEndpoint,
+				failureMsg: "failed to configure the endpoint.properties",
+				run:        func() error { return endpoint.Configure(log, inputDir, containerConfigDir) },
+			},
+			{
+				name:       StepMetadata,
+				failureMsg: "failed to configure the enrichment files",
+				run: func() error {
+					return metadata.Configure(log, containerConfigDir, podAttr, containerAttr, withDeprecatedAttributes)
+				},
+			},
+		})
+	})
+	if err != nil {
+		var report *Report
+		if errors.As(err, &report) {
+			log.Info("finished enrichment with errors", "config-directory", configDir, "failed containers", report.Containers())
+		}
+
+		return err
+	}
+
+	log.Info("finished enrichment", "config-directory", configDir, "input-directory", inputDir)
+
+	return nil
+}
+
+// forEachContainer runs fn for every container, at most --concurrency containers at the same time, with a logger for the container.
+// The errors are collected in the order of the containers, so the result does not depend on the scheduling.
+// Unless the errors are isolated per container (see --continue-on-error), no further container is started after a failure,
+// and only the first error of the first failed container is returned.
+func forEachContainer(log logr.Logger, containerAttrs []container.Attributes, fn func(log logr.Logger, containerAttr container.Attributes) []*ContainerError) error {
+	results := make([][]*ContainerError, len(containerAttrs))
+	workers := make(chan struct{}, max(concurrency, 1))
+
+	var (
+		wg     sync.WaitGroup
+		failed atomic.Bool
+	)
+
+	for i, containerAttr := range containerAttrs {
+		workers <- struct{}{}
+
+		if failed.Load() && !continueOnError {
+			<-workers
 
-			return err
+			break
 		}
 
-		err = metadata.Configure(log, containerConfigDir, podAttr, containerAttr, withDeprecatedAttributes)
-		if err != nil {
-			log.Info("failed to configure the enrichment files", "config-directory", containerConfigDir)
+		wg.Go(func() {
+			defer func() { <-workers }()
 
-			return err
+			results[i] = fn(log.WithValues("container", containerAttr.ContainerName), containerAttr)
+			if len(results[i]) > 0 {
+				failed.Store(true)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	var report Report
+
+	for _, errs := range results {
+		if len(errs) > 0 && !continueOnError {
+			return errs[0]
 		}
+
+		report.add(errs...)
 	}
 
-	log.Info("finished enrichment", "config-directory", configDir, "input-directory", inputDir)
+	return report.err()
+}
 
-	return nil
+// step is a single step of the configuration or enrichment of a container.
+type step struct {
+	run        func() error
+	name       string
+	failureMsg string
+}
+
+// runSteps runs the steps of a container in order, and stops at the first failing one.
+// If the errors are isolated per container (see --continue-on-error), the remaining steps still run, so all errors of the container are reported.
+func runSteps(log logr.Logger, containerName, containerConfigDir string, steps []step) []*ContainerError {
+	var errs []*ContainerError
+
+	for _, step := range steps {
+		err := step.run()
+		if err == nil {
+			continue
+		}
+
+		log.Info(step.failureMsg, "config-directory", containerConfigDir)
+
+		errs = append(errs, &ContainerError{Container: containerName, Step: step.name, Err: err})
+
+		if !continueOnError {
+			break
+		}
+	}
+
+	return errs
 }
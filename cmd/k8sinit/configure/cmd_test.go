@@ -2,6 +2,7 @@ package configure
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -14,7 +15,9 @@ import (
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc/ruxit"
 	fsutils "github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
 	"github.com/go-logr/zapr"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -173,6 +176,144 @@ func TestEnrichWithMetadata(t *testing.T) {
 	})
 }
 
+func TestContinueOnError(t *testing.T) {
+	const brokenContainerName = "broken-container-name"
+
+	podAttributes = []string{
+		"k8s.pod.name=pod1",
+		"k8s.pod.uid=123",
+		"k8s.namespace.name=default",
+	}
+
+	containerAttributes = []string{
+		`{"k8s.container.name": "` + brokenContainerName + `"}`,
+		`{"k8s.container.name": "test-container-name"}`,
+	}
+
+	setup := func(t *testing.T) string {
+		t.Helper()
+
+		baseTempDir := filepath.Join(t.TempDir(), "path")
+		configDir = filepath.Join(baseTempDir, "conf")
+		inputDir = filepath.Join(baseTempDir, "input")
+		installPath = filepath.Join(baseTempDir, "install")
+		targetFolder := filepath.Join(baseTempDir, "target")
+
+		setupInputFs(t, inputDir)
+		setupTargetFs(t, targetFolder)
+
+		// a file in place of the config directory of the container makes every step fail
+		require.NoError(t, fsutils.CreateFile(filepath.Join(configDir, brokenContainerName), "broken"))
+
+		return targetFolder
+	}
+
+	t.Run("stops at the first failing container by default", func(t *testing.T) {
+		continueOnError = false
+
+		previousConcurrency := concurrency
+		concurrency = 1
+
+		t.Cleanup(func() { concurrency = previousConcurrency })
+
+		err := SetupOneAgent(testLog, setup(t))
+		require.Error(t, err)
+
+		var containerErr *ContainerError
+		require.ErrorAs(t, err, &containerErr)
+		assert.Equal(t, brokenContainerName, containerErr.Container)
+		assert.Equal(t, StepPMC, containerErr.Step)
+		assert.False(t, IsReport(err))
+
+		assert.NoDirExists(t, filepath.Join(configDir, "test-container-name"))
+	})
+
+	t.Run("configures every container and reports all errors", func(t *testing.T) {
+		continueOnError = true
+		defer func() { continueOnError = false }()
+
+		setupErr := SetupOneAgent(testLog, setup(t))
+		require.True(t, IsReport(setupErr))
+
+		enrichErr := EnrichWithMetadata(testLog, alwaysEnableDeprecatedAttributes)
+		require.True(t, IsReport(enrichErr))
+
+		report := JoinReports(setupErr, enrichErr)
+		require.NotNil(t, report)
+		assert.Equal(t, []string{brokenContainerName}, report.Containers())
+
+		failedSteps := report.ByContainer()[brokenContainerName]
+		for _, step := range []string{StepPMC, StepConf, StepCurl, StepCA, StepPGC, StepEndpoint, StepMetadata} {
+			assert.Contains(t, failedSteps, step)
+		}
+
+		assert.Contains(t, report.Error(), "7 step(s) failed for 1 container(s):")
+		assert.Contains(t, report.Error(), "container "+brokenContainerName+": pmc: ")
+
+		// the other container is fully configured: pmc(1) + conf(1) + curl(1) + ca(2) + pgc(1) + endpoint(1) + metadata(2)
+		assert.Equal(t, 9, countFiles(t, filepath.Join(configDir, "test-container-name")))
+	})
+}
+
+func TestConcurrency(t *testing.T) {
+	const containerCount = 20
+
+	podAttributes = []string{"k8s.pod.name=pod1"}
+
+	containerNames := make([]string, 0, containerCount)
+	containerAttributes = make([]string, 0, containerCount)
+
+	for i := range containerCount {
+		name := fmt.Sprintf("container-%02d", i)
+		containerNames = append(containerNames, name)
+		containerAttributes = append(containerAttributes, `{"k8s.container.name": "`+name+`"}`)
+	}
+
+	previousConcurrency := concurrency
+	concurrency = 3
+
+	t.Cleanup(func() { concurrency = previousConcurrency })
+
+	baseTempDir := filepath.Join(t.TempDir(), "path")
+	configDir = filepath.Join(baseTempDir, "conf")
+	inputDir = filepath.Join(baseTempDir, "input")
+	installPath = filepath.Join(baseTempDir, "install")
+	targetFolder := filepath.Join(baseTempDir, "target")
+
+	setupInputFs(t, inputDir)
+	setupTargetFs(t, targetFolder)
+
+	t.Run("every container is configured", func(t *testing.T) {
+		require.NoError(t, SetupOneAgent(testLog, targetFolder))
+		require.NoError(t, EnrichWithMetadata(testLog, alwaysEnableDeprecatedAttributes))
+
+		expected, err := os.ReadFile(pmc.GetDestinationRuxitAgentProcFilePath(filepath.Join(configDir, containerNames[0])))
+		require.NoError(t, err)
+
+		for _, name := range containerNames {
+			assert.Equal(t, 9, countFiles(t, filepath.Join(configDir, name)), name)
+
+			content, err := os.ReadFile(pmc.GetDestinationRuxitAgentProcFilePath(filepath.Join(configDir, name)))
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(content))
+		}
+	})
+
+	t.Run("errors are reported in the order of the containers", func(t *testing.T) {
+		continueOnError = true
+		defer func() { continueOnError = false }()
+
+		// a missing source ruxitagentproc.conf fails the pmc step of every container
+		require.NoError(t, os.Remove(pmc.GetSourceRuxitAgentProcFilePath(targetFolder)))
+
+		err := SetupOneAgent(testLog, targetFolder)
+
+		report := JoinReports(err)
+		require.NotNil(t, report)
+		assert.Equal(t, containerNames, report.Containers())
+	})
+}
+
 func countFiles(t *testing.T, path string) int {
 	t.Helper()
 
@@ -201,8 +342,8 @@ func setupInputFs(t *testing.T, inputDir string) {
 	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, endpoint.InputFileName), "endpoint"))
 
 	// ca
-	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, ca.TrustedCertsInputFile), "trusted"))
-	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, ca.AgCertsInputFile), "ag"))
+	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, ca.TrustedCertsInputFile), tests.NewValidCertificatePEM(t, "trusted")))
+	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, ca.AgCertsInputFile), tests.NewValidCertificatePEM(t, "activegate")))
 
 	// curl
 	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, curl.InputFileName), "123"))
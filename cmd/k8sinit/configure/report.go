@@ -0,0 +1,127 @@
+package configure
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The steps of the configuration and enrichment of a container, as reported in a ContainerError.
+const (
+	StepPMC      = "pmc"
+	StepConf     = "conf"
+	StepCurl     = "curl"
+	StepCA       = "ca"
+	StepPGC      = "pgc"
+	StepEndpoint = "endpoint"
+	StepMetadata = "metadata"
+)
+
+// ContainerError is the error of a single step of the configuration or enrichment of a container.
+type ContainerError struct {
+	Err       error
+	Container string
+	Step      string
+}
+
+func (e *ContainerError) Error() string {
+	return fmt.Sprintf("container %s: %s: %v", e.Container, e.Step, e.Err)
+}
+
+func (e *ContainerError) Unwrap() error {
+	return e.Err
+}
+
+// Report aggregates the errors of all containers, when the errors are isolated per container (see --continue-on-error).
+// The containers which are not part of the report are fully configured.
+type Report struct {
+	Errors []*ContainerError
+}
+
+func (r *Report) Error() string {
+	lines := make([]string, 0, len(r.Errors)+1)
+	lines = append(lines, fmt.Sprintf("%d step(s) failed for %d container(s):", len(r.Errors), len(r.Containers())))
+
+	for _, err := range r.Errors {
+		lines = append(lines, "  "+err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (r *Report) Unwrap() []error {
+	errs := make([]error, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// Containers returns the names of the failed containers, in the order of their first error.
+func (r *Report) Containers() []string {
+	var containers []string
+
+	seen := map[string]bool{}
+
+	for _, err := range r.Errors {
+		if !seen[err.Container] {
+			seen[err.Container] = true
+			containers = append(containers, err.Container)
+		}
+	}
+
+	return containers
+}
+
+// ByContainer returns the error messages per failed container and step, to be logged as a structured value.
+func (r *Report) ByContainer() map[string]map[string]string {
+	result := map[string]map[string]string{}
+
+	for _, err := range r.Errors {
+		if result[err.Container] == nil {
+			result[err.Container] = map[string]string{}
+		}
+
+		result[err.Container][err.Step] = err.Err.Error()
+	}
+
+	return result
+}
+
+// IsReport returns true if the error is a Report, i.e. every container has been tried, the other errors abort the configuration.
+func IsReport(err error) bool {
+	var report *Report
+
+	return errors.As(err, &report)
+}
+
+// JoinReports combines the Reports of the configuration and the enrichment into one, it returns nil if there are no errors.
+func JoinReports(errs ...error) *Report {
+	var joined Report
+
+	for _, err := range errs {
+		var report *Report
+		if errors.As(err, &report) {
+			joined.Errors = append(joined.Errors, report.Errors...)
+		}
+	}
+
+	if len(joined.Errors) == 0 {
+		return nil
+	}
+
+	return &joined
+}
+
+func (r *Report) add(errs ...*ContainerError) {
+	r.Errors = append(r.Errors, errs...)
+}
+
+func (r *Report) err() error {
+	if len(r.Errors) == 0 {
+		return nil
+	}
+
+	return r
+}
@@ -1,6 +1,7 @@
 package move
 
 import (
+	"context"
 	"strings"
 
 	impl "github.com/Dynatrace/dynatrace-bootstrapper/pkg/move"
@@ -28,7 +29,8 @@ func AddFlags(cmd *cobra.Command) {
 
 // Execute moves the contents of a folder to another via copying.
 // This could be a simple os.Rename, however that will not work if the source and target are on different disk.
-func Execute(log logr.Logger, from, to string) error {
+// The copy is aborted when the context is cancelled, in case of a --work folder, the partial copy is cleaned up.
+func Execute(ctx context.Context, log logr.Logger, from, to string) error {
 	copyFunc := impl.SimpleCopy
 
 	if technology != "" && strings.TrimSpace(technology) != AllTechValue {
@@ -39,7 +41,7 @@ func Execute(log logr.Logger, from, to string) error {
 		copyFunc = impl.Atomic(workFolder, copyFunc)
 	}
 
-	err := copyFunc(log, from, to)
+	err := copyFunc(ctx, log, from, to)
 	if err != nil {
 		return err
 	}
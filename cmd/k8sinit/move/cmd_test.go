@@ -37,7 +37,7 @@ func TestExecute(t *testing.T) {
 
 		technology = " " + AllTechValue + " "
 
-		err := Execute(testLog, sourceDir, targetDir)
+		err := Execute(t.Context(), testLog, sourceDir, targetDir)
 		require.NoError(t, err)
 
 		verifyTarget(t, targetDir, files)
@@ -86,7 +86,7 @@ func TestExecute(t *testing.T) {
 
 		technology = technologyList
 
-		err := Execute(testLog, sourceDir, targetDir)
+		err := Execute(t.Context(), testLog, sourceDir, targetDir)
 		require.NoError(t, err)
 
 		verifyTarget(t, targetDir, expectedFiles, file2)
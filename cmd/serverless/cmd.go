@@ -1,12 +1,20 @@
 package serverless
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/serverless/platform"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/health"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/exit"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/flags"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/version"
 	"github.com/go-logr/logr"
@@ -19,30 +27,37 @@ import (
 const (
 	Use = "serverless"
 
-	TargetFolderFlag = "target"
-	KeepAliveFlag    = "keep-alive"
-	SourceFolderFlag = "source"
-	TechnologyFlag   = "technology"
-	WorkFolderFlag   = "work"
-	DebugFlag        = "debug"
+	TargetFolderFlag      = "target"
+	KeepAliveFlag         = "keep-alive"
+	SourceFolderFlag      = "source"
+	TechnologyFlag        = "technology"
+	WorkFolderFlag        = "work"
+	DebugFlag             = "debug"
+	CheckIntervalFlag     = "check-interval"
+	HealthAddrFlag        = "health-addr"
+	DeploymentTimeoutFlag = "deployment-timeout"
+	ReconcileIntervalFlag = "reconcile-interval"
+	WatchSourceFlag       = "watch-source"
 )
 
-const (
-	defaultCodeModulesPathInSourceFolder = "/opt/dynatrace/oneagent"
-	defaultWorkFolderPath                = "/home/dynatrace/oneagent/work"
-)
+// ExitCodeDeploymentTimeout is the exit code if the OneAgent deployment was not completed within the deployment timeout.
+const ExitCodeDeploymentTimeout = 3
 
-const checkDeploymentStatusInterval = 10 * time.Second
+const defaultCheckDeploymentStatusInterval = 10 * time.Second
 
 func New() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:                Use,
+		PreRunE:            preRun,
 		RunE:               run,
 		FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
 		Version:            version.Version,
 		Short:              "Deploy the OneAgent CodeModule in a Cloud environment",
 	}
 
+	// the platform is detected when the command is created, as it supplies the defaults of the flags
+	detectedPlatform = platform.Detect(platform.OSEnv(), platform.Providers...)
+
 	addFlags(cmd)
 
 	return cmd
@@ -52,48 +67,100 @@ var (
 	logger  logr.Logger
 	isDebug bool
 
+	detectedPlatform platform.Platform
+
 	sourceFolder   string
 	targetFolder   string
 	workBaseFolder string
 	technology     string
 	keepAlive      bool
+	checkInterval  time.Duration
+	healthAddr     string
+
+	deploymentTimeout time.Duration
+	reconcileInterval time.Duration
+	watchSource       bool
 )
 
 func addFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&targetFolder, TargetFolderFlag, "", "Base path where to copy the CodeModule to.")
+	defaultPaths := detectedPlatform.DefaultPaths()
 
-	err := cmd.MarkFlagRequired(TargetFolderFlag)
-	if err != nil {
-		panic(err)
+	cmd.Flags().StringVar(&targetFolder, TargetFolderFlag, defaultPaths.Target, "Base path where to copy the CodeModule to. Only required if the platform has no default.")
+
+	// the target has to be on a storage shared by all instances, so it can't be defaulted on a generic platform
+	if defaultPaths.Target == "" {
+		err := cmd.MarkFlagRequired(TargetFolderFlag)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	cmd.Flags().BoolVar(&keepAlive, KeepAliveFlag, false, "Keep the Bootstrapper process running even after deployment is finished.")
 
-	err = cmd.MarkFlagRequired(KeepAliveFlag)
+	err := cmd.MarkFlagRequired(KeepAliveFlag)
 	if err != nil {
 		panic(err)
 	}
 
-	cmd.Flags().StringVar(&sourceFolder, SourceFolderFlag, defaultCodeModulesPathInSourceFolder, "(Optional) Base path where to copy the CodeModule from.")
+	cmd.Flags().StringVar(&sourceFolder, SourceFolderFlag, defaultPaths.Source, "(Optional) Base path where to copy the CodeModule from.")
 	cmd.Flags().StringVar(&technology, TechnologyFlag, "", "(Optional) Comma-separated list of CodeModule technologies to deploy.")
-	cmd.Flags().StringVar(&workBaseFolder, WorkFolderFlag, defaultWorkFolderPath, "(Optional) Base path to a tmp working folder used for atomic copy. Must be on the same disk as the target.")
+	cmd.Flags().StringVar(&workBaseFolder, WorkFolderFlag, defaultPaths.Work, "(Optional) Base path to a tmp working folder used for atomic copy. Must be on the same disk as the target.")
 	cmd.Flags().BoolVar(&isDebug, DebugFlag, false, "(Optional) Enables debug logs.")
+	cmd.Flags().DurationVar(&checkInterval, CheckIntervalFlag, defaultCheckDeploymentStatusInterval, "(Optional) Interval for checking the deployment status while waiting for another instance to deploy. Changes of the active symlink are detected immediately if the file system supports inotify.")
+	cmd.Flags().DurationVar(&deploymentTimeout, DeploymentTimeoutFlag, 0, "(Optional) Maximum time to wait in keep-alive mode for another instance to complete the deployment. After that, the deployment is taken over if the lock is stale, otherwise the process exits with code 3. Waits indefinitely if not set.")
+	cmd.Flags().DurationVar(&reconcileInterval, ReconcileIntervalFlag, 0, "(Optional) Interval for checking the deployment status in keep-alive mode once it is deployed, a regressed deployment (e.g., a removed active symlink or OneAgent folder) is repaired. Disabled if not set.")
+	cmd.Flags().BoolVar(&watchSource, WatchSourceFlag, false, "(Optional) Watch the installer.version of the source in keep-alive mode, and deploy a new OneAgent version as soon as it appears. The application has to be restarted to load the new version.")
+	addConfigureFlags(cmd)
+
+	flags.AddConfigFileFlag(cmd)
+
+	cmd.Flags().StringVar(&healthAddr, HealthAddrFlag, "", "(Optional) Address (e.g. ':8080') to serve the /healthz, /readyz and /status endpoints on in keep-alive mode.")
+}
+
+// preRun applies the environment variables and the config file, so its values are taken into account when validating the required flags.
+func preRun(cmd *cobra.Command, _ []string) error {
+	return flags.Apply(cmd)
 }
 
-func run(_ *cobra.Command, _ []string) (err error) {
+func run(cmd *cobra.Command, _ []string) (err error) {
 	if logger.IsZero() {
 		setupLogger()
 	}
 
+	// A SIGINT/SIGTERM aborts an ongoing deployment (cleaning up the work folder and releasing the lock) or ends keep-alive mode.
+	ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
 	if isDebug {
 		logger.Info("debug logs enabled")
 	}
 
+	flags.LogSources(logger, cmd)
+
 	version.Print(logger)
 
-	logger.Info("Running in serverless mode...")
+	logger.Info("Running in serverless mode...", "platform", detectedPlatform.Name())
+
+	if keepAlive && healthAddr != "" {
+		healthServer := health.NewServer(logger, healthAddr, func() deployment.AgentDeploymentInfo {
+			return deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology)
+		})
 
-	result := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder)
+		if err := healthServer.Start(); err != nil {
+			logger.Error(err, "failed to start the health server")
+
+			return err
+		}
+
+		defer healthServer.Shutdown()
+	}
+
+	// the metadata is not needed to run the application, so a failure must not prevent the deployment
+	if enrichErr := enrichWithMetadata(); enrichErr != nil {
+		logger.Error(enrichErr, "failed to enrich with metadata")
+	}
+
+	result := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology)
 
 	var agentAlreadyDeployed bool
 
@@ -101,62 +168,156 @@ func run(_ *cobra.Command, _ []string) (err error) {
 	case result.Error != nil:
 		logger.Error(result.Error, "failed to check OneAgent deployment status. Skipping deployment.", "status", result.Status.String())
 		err = result.Error
-	case result.Status == deployment.Deployed:
+	case result.Status == deployment.Deployed && isAgentConfigured(result.AgentVersion):
 		logger.Info("OneAgent is already deployed", "OneAgent version", result.AgentVersion)
 
 		agentAlreadyDeployed = true
 	default:
 		logger.Info("OneAgent deployment status", "status", result.Status)
 
-		agentAlreadyDeployed, err = deployment.DeployOneAgent(logger, sourceFolder, targetFolder, workBaseFolder, technology)
+		agentAlreadyDeployed, err = deployment.DeployOneAgent(ctx, logger, sourceFolder, targetFolder, workBaseFolder, technology, getDeploymentOptions()...)
 		if err != nil {
+			if ctx.Err() != nil {
+				logger.Info("OneAgent deployment was interrupted by a signal")
+
+				return err
+			}
+
 			logger.Error(err, "OneAgent deployment has failed")
 		}
 	}
 
 	if keepAlive {
-		keepProcessAlive(!agentAlreadyDeployed)
+		if keepAliveErr := keepProcessAlive(ctx, !agentAlreadyDeployed); keepAliveErr != nil {
+			return keepAliveErr
+		}
 	}
 
 	return err
 }
 
+// isAgentConfigured returns true if the OneAgent configuration, if requested, was already rendered for the given version.
+func isAgentConfigured(agentVersion string) bool {
+	configured, err := deployment.IsConfigured(agentVersion, getDeploymentOptions()...)
+	if err != nil {
+		logger.Error(err, "failed to check OneAgent configuration status")
+
+		return false
+	}
+
+	return configured
+}
+
 // keepProcessAlive keeps the process alive.
-// If monitorDeployment is true, the OneAgent deployment status will be periodically checked until deployment is complete.
-func keepProcessAlive(monitorDeployment bool) {
+// If monitorDeployment is true, the OneAgent deployment status will be checked on every change of the `active` symlink
+// (or periodically, if changes can't be detected) until deployment is complete.
+// Afterward, the deployment is reconciled if a reconcile interval is set, and the source is watched for a new version if enabled.
+// The process is kept alive until the context is cancelled (i.e. on SIGINT/SIGTERM).
+// Returns an error if the deployment is not complete within the deployment timeout.
+func keepProcessAlive(ctx context.Context, monitorDeployment bool) error {
 	logger.Info("Running in keep-alive mode...")
 
 	if monitorDeployment {
-		var lastErr error
-
-		// Periodically check the OneAgent deployment status until it is deployed.
-		// In a multi-instance environment, another Bootstrapper may handle the deployment.
-	monitorLoop:
-		for {
-			result := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder)
-			switch {
-			case result.Error != nil:
-				// Log the deployment error only if it differs from the previous one to avoid log spam
-				if lastErr == nil || result.Error.Error() != lastErr.Error() {
-					logger.Error(result.Error, "failed to check OneAgent deployment status", "status", result.Status.String())
-					lastErr = result.Error
-				}
-			case result.Status == deployment.Deployed:
-				logger.Info("OneAgent has been successfully deployed", "OneAgent version", result.AgentVersion)
-
-				break monitorLoop
-			default:
-				log.Debug(logger, "The required OneAgent version is not deployed", "status", result.Status.String())
+		if err := waitForDeployment(ctx); err != nil {
+			return err
+		}
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	currentVersion := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology).AgentVersion
+	setDeployedVersion(currentVersion)
+
+	if watchSource {
+		wg.Go(func() {
+			watchSourceVersion(ctx, currentVersion)
+		})
+	}
+
+	if reconcileInterval > 0 {
+		reconcileDeployment(ctx)
+
+		return nil
+	}
+
+	// Keep the process alive when the deployment check is completed
+	<-ctx.Done()
+
+	return nil
+}
+
+// waitForDeployment blocks until the required OneAgent version is deployed.
+// In a multi-instance environment, another Bootstrapper may handle the deployment.
+// If the deployment is not complete within the deployment timeout, it tries to take over the deployment itself.
+func waitForDeployment(ctx context.Context) error {
+	waitCtx := ctx
+
+	if deploymentTimeout > 0 {
+		var cancel context.CancelFunc
+
+		waitCtx, cancel = context.WithTimeout(ctx, deploymentTimeout)
+		defer cancel()
+	}
+
+	var lastErr error
+
+	waiter := deployment.NewActiveLinkWaiter(logger, targetFolder, checkInterval)
+	defer waiter.Close()
+
+	// Check the OneAgent deployment status on every change of the `active` symlink until it is deployed.
+	for {
+		result := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology)
+		switch {
+		case result.Error != nil:
+			// Log the deployment error only if it differs from the previous one to avoid log spam
+			if lastErr == nil || result.Error.Error() != lastErr.Error() {
+				logger.Error(result.Error, "failed to check OneAgent deployment status", "status", result.Status.String())
+				lastErr = result.Error
 			}
+		case result.Status == deployment.Deployed:
+			logger.Info("OneAgent has been successfully deployed", "OneAgent version", result.AgentVersion)
 
-			time.Sleep(checkDeploymentStatusInterval)
+			return nil
+		default:
+			log.Debug(logger, "The required OneAgent version is not deployed", "status", result.Status.String())
+		}
+
+		if err := waiter.Wait(waitCtx); err != nil {
+			if ctx.Err() != nil {
+				// interrupted by a signal, nothing left to wait for
+				return nil
+			}
+
+			return takeOverDeployment(ctx)
 		}
 	}
+}
 
-	// Keep the process alive when the deployment check is completed
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	<-sigCh
+// takeOverDeployment tries to deploy OneAgent after the deployment timeout has passed.
+// It only succeeds if the deployment lock is not held anymore, or is stale (e.g., the lock holder died).
+func takeOverDeployment(ctx context.Context) error {
+	logger.Info("OneAgent deployment was not completed in time, trying to take over the deployment", "deployment timeout", deploymentTimeout.String())
+
+	deployed, err := deployment.DeployOneAgent(ctx, logger, sourceFolder, targetFolder, workBaseFolder, technology, getDeploymentOptions()...)
+	if err == nil && !deployed {
+		// another instance may have completed the deployment in the meantime
+		result := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology)
+		if result.Error != nil || result.Status != deployment.Deployed {
+			err = errors.New("another instance still holds the deployment lock")
+		}
+	}
+
+	if err != nil {
+		err = fmt.Errorf("OneAgent deployment was not completed within %s: %w", deploymentTimeout, err)
+		logger.Error(err, "giving up waiting for the OneAgent deployment")
+
+		return exit.WithCode(ExitCodeDeploymentTimeout, err)
+	}
+
+	logger.Info("OneAgent has been successfully deployed after taking over the deployment")
+
+	return nil
 }
 
 func setupLogger() {
@@ -7,7 +7,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/serverless/platform"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/lock"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/exit"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/flags"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
 	"github.com/stretchr/testify/require"
 )
@@ -43,6 +47,20 @@ func TestServerlessCmd(t *testing.T) {
 		require.ErrorContains(t, err, "required flag(s) \"keep-alive\" not set")
 	})
 
+	t.Run("platform with a default target ==> 'target' parameter is not required", func(t *testing.T) {
+		t.Setenv(platform.AzureSiteNameEnv, "my-site")
+
+		cmd := New()
+
+		require.Equal(t, "/home/dynatrace", cmd.Flags().Lookup(TargetFolderFlag).DefValue)
+		require.Equal(t, "/home/dynatrace/oneagent/work", cmd.Flags().Lookup(WorkFolderFlag).DefValue)
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "required flag(s) \"keep-alive\" not set")
+	})
+
 	t.Run("no error if all required parameters are provided", func(t *testing.T) {
 		const agentVersion = "1.327.30.20251107-111521"
 
@@ -194,6 +212,146 @@ func TestServerlessCmd(t *testing.T) {
 	})
 }
 
+func TestConfigFile(t *testing.T) {
+	t.Run("the options can be set in the config file", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+
+		configFile := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(configFile, []byte(`{
+			"source": "`+sourceDir+`",
+			"target": "`+targetDir+`",
+			"work": "`+t.TempDir()+`",
+			"keep-alive": false,
+			"check-interval": "1s"
+		}`), 0o600))
+
+		cmd := New()
+		cmd.SetArgs([]string{"--config", configFile})
+		require.NoError(t, cmd.Execute())
+
+		require.Equal(t, deployment.Deployed, deployment.CheckAgentDeploymentStatus(sourceDir, targetDir, "").Status)
+	})
+
+	t.Run("the published schema is up to date", func(t *testing.T) {
+		tests.RequireGoldenJSON(t, "../../schema/serverless.schema.json", flags.Schema("dynatrace-bootstrapper serverless config file", New().Flags()))
+	})
+}
+
+func TestDeploymentTimeout(t *testing.T) {
+	t.Run("exit with a distinct code if another instance holds the lock after the deployment timeout", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		workDir := t.TempDir()
+		holdDeploymentLock(t, workDir, time.Now())
+
+		cmd := New()
+		cmd.SetArgs([]string{"--keep-alive=true", "--source", sourceDir, "--target", t.TempDir(), "--work", workDir,
+			"--deployment-timeout=1s", "--check-interval=100ms"})
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t, ExitCodeDeploymentTimeout, exit.Code(err))
+		require.ErrorContains(t, err, "another instance still holds the deployment lock")
+
+		tests.RequireLogMessage(t, logsObserver, "giving up waiting for the OneAgent deployment")
+	})
+
+	t.Run("take over the deployment if the lock became stale", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		// the lock is still fresh on startup, but becomes stale before the deployment timeout passes
+		workDir := t.TempDir()
+		holdDeploymentLock(t, workDir, time.Now().Add(-lock.DefaultStaleTimeout+time.Second))
+
+		targetDir := t.TempDir()
+		finished := make(chan error, 1)
+
+		go func() {
+			cmd := New()
+			cmd.SetArgs([]string{"--keep-alive=true", "--source", sourceDir, "--target", targetDir, "--work", workDir,
+				"--deployment-timeout=2s", "--check-interval=100ms"})
+
+			finished <- cmd.Execute()
+		}()
+
+		require.Eventually(t, func() bool {
+			return deployment.CheckAgentDeploymentStatus(sourceDir, targetDir, "").Status == deployment.Deployed
+		}, 10*time.Second, 100*time.Millisecond)
+
+		select {
+		case err := <-finished:
+			t.Fatalf("the Bootstrapper finished execution in 'keep-alive=true' mode: %v", err)
+		default:
+		}
+
+		tests.RequireLogMessage(t, logsObserver, "OneAgent has been successfully deployed after taking over the deployment")
+	})
+}
+
+func TestSignalHandling(t *testing.T) {
+	t.Run("keep-alive mode ends on SIGTERM", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		finished := make(chan error, 1)
+
+		go func() {
+			cmd := New()
+			cmd.SetArgs([]string{"--keep-alive=true", "--source", sourceDir, "--target", targetDir})
+
+			finished <- cmd.Execute()
+		}()
+
+		// the signal handler is registered before keep-alive mode starts
+		require.Eventually(t, func() bool {
+			return len(logsObserver.FilterMessage("Running in keep-alive mode...")) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+
+		select {
+		case err := <-finished:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the Bootstrapper did not finish after SIGTERM")
+		}
+	})
+}
+
+// holdDeploymentLock simulates another instance holding the deployment lock since the given time.
+func holdDeploymentLock(t *testing.T, workDir string, since time.Time) {
+	t.Helper()
+
+	logger, _ := tests.NewTestLogger()
+
+	lockFilePath := filepath.Join(workDir, "deployment.lock")
+	acquired, err := lock.New(logger, lockFilePath).TryAcquire()
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NoError(t, os.Chtimes(lockFilePath, since, since))
+}
+
 // setupServerlessLogger sets the test logger as the default Serverless logger
 // and returns a CapturedLogs instance to be used in tests for log message assertions.
 func setupServerlessLogger() *tests.CapturedLogs {
@@ -0,0 +1,288 @@
+package serverless
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/fingerprint"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/ca"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/curl"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pgc"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/preload"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/version"
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+)
+
+const (
+	InputFolderFlag  = "input-directory"
+	ConfigFolderFlag = "config-directory"
+	InstallPathFlag  = "install-path"
+	SystemCAFileFlag = "system-ca-file"
+
+	// the config folder is shared, only the agent of the application has to be able to read it
+	configDirPerm os.FileMode = 0o755
+
+	// fingerprintSuffixLength is the number of hex digits of the input fingerprint used as the suffix of the rendered config folder.
+	fingerprintSuffixLength = 12
+)
+
+var (
+	inputDir     string
+	configDir    string
+	installPath  string
+	systemCAFile string
+)
+
+func addConfigureFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&inputDir, InputFolderFlag, "", "(Optional) Base path where to look for the configuration files.")
+	cmd.Flags().StringVar(&configDir, ConfigFolderFlag, "", "(Optional) Base path in the shared storage where to put the configuration files, they are put in a subfolder per OneAgent version. The metadata-enrichment files are put in a subfolder per instance.")
+	cmd.Flags().StringVar(&installPath, InstallPathFlag, "", "(Optional) Path where the application loads the CodeModule from. Defaults to the 'active' symlink in the target folder.")
+	cmd.Flags().StringVar(&systemCAFile, SystemCAFileFlag, "", "(Optional) Path to a CA bundle of the system (e.g., /etc/ssl/certs/ca-certificates.crt), whose certificates are merged into the certificates trusted by the CodeModule.")
+}
+
+// getDeploymentOptions returns the options for deployment.DeployOneAgent according to the flags and the detected platform.
+func getDeploymentOptions() []deployment.Option {
+	var opts []deployment.Option
+
+	if instanceID, err := detectedPlatform.InstanceID(); err == nil {
+		opts = append(opts, deployment.WithInstanceID(instanceID))
+	}
+
+	if configDir != "" && inputDir != "" {
+		opts = append(opts, deployment.WithConfigurator(configurator{}))
+	}
+
+	return opts
+}
+
+// configurator renders the OneAgent configuration into <config-directory>/<agent-version>-<fingerprint>,
+// <config-directory>/<agent-version> is a symlink to the folder rendered from the current inputs.
+type configurator struct{}
+
+var _ deployment.Configurator = configurator{}
+
+// IsConfigured returns true if the configuration of the agent version was rendered from the current inputs,
+// so a change of the input files, the system CA file or the install path renders the configuration again.
+func (configurator) IsConfigured(agentVersion string) (bool, error) {
+	renderedConfigDir, err := getRenderedConfigFolder(agentVersion)
+	if err != nil {
+		return false, err
+	}
+
+	linkTarget, err := os.Readlink(getVersionedConfigFolder(agentVersion))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	if linkTarget != filepath.Base(renderedConfigDir) {
+		return false, nil
+	}
+
+	_, err = os.Stat(renderedConfigDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Configure renders the configuration in a temporary folder next to the versioned config folder,
+// which is then atomically renamed, so a partially rendered configuration is never used.
+// Afterward, the <config-directory>/<agent-version> symlink is switched to it atomically,
+// the previously rendered folder is left in place, as it may still be in use by the applications.
+func (configurator) Configure(_ context.Context, log logr.Logger, agentFolder, agentVersion string) (err error) {
+	renderedConfigDir, err := getRenderedConfigFolder(agentVersion)
+	if err != nil {
+		return err
+	}
+
+	log.Info("starting configuration", "config-directory", renderedConfigDir, "input-directory", inputDir)
+
+	if err := os.MkdirAll(configDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the config directory: %w", err)
+	}
+
+	// the inputs may have changed back to an already rendered configuration
+	if _, err := os.Stat(renderedConfigDir); os.IsNotExist(err) {
+		if err := renderConfig(log, agentFolder, renderedConfigDir); err != nil {
+			return err
+		}
+	}
+
+	if err := switchConfigLink(log, agentVersion, renderedConfigDir); err != nil {
+		return err
+	}
+
+	log.Info("finished oneagent configuration", "config-directory", getVersionedConfigFolder(agentVersion), "input-directory", inputDir)
+
+	return nil
+}
+
+func renderConfig(log logr.Logger, agentFolder, renderedConfigDir string) error {
+	workDir, err := os.MkdirTemp(configDir, ".config-work-*")
+	if err != nil {
+		return fmt.Errorf("failed to create the temporary config work folder: %w", err)
+	}
+
+	defer func() {
+		if cleanupErr := os.RemoveAll(workDir); cleanupErr != nil {
+			log.Error(cleanupErr, "failed to cleanup the config work folder")
+		}
+	}()
+
+	if err := configureOneAgent(log, agentFolder, workDir); err != nil {
+		return err
+	}
+
+	// os.MkdirTemp always creates the folder with 0700, but the agent of the application has to be able to read it
+	if err := os.Chmod(workDir, configDirPerm); err != nil {
+		return fmt.Errorf("failed to set the permissions of the config folder: %w", err)
+	}
+
+	if err := os.Rename(workDir, renderedConfigDir); err != nil {
+		return fmt.Errorf("failed to move the config folder: %w", err)
+	}
+
+	return nil
+}
+
+// switchConfigLink creates or updates the <config-directory>/<agent-version> symlink by renaming a temporary symlink onto it.
+func switchConfigLink(log logr.Logger, agentVersion, renderedConfigDir string) error {
+	workDir, err := os.MkdirTemp(configDir, ".config-link-work-*")
+	if err != nil {
+		return fmt.Errorf("failed to create the temporary config link work folder: %w", err)
+	}
+
+	defer func() {
+		if cleanupErr := os.RemoveAll(workDir); cleanupErr != nil {
+			log.Error(cleanupErr, "failed to cleanup the config link work folder")
+		}
+	}()
+
+	tmpLink := filepath.Join(workDir, agentVersion)
+	if err := os.Symlink(filepath.Base(renderedConfigDir), tmpLink); err != nil {
+		return fmt.Errorf("failed to create the temporary config symlink: %w", err)
+	}
+
+	if err := os.Rename(tmpLink, getVersionedConfigFolder(agentVersion)); err != nil {
+		return fmt.Errorf("failed to rename the temporary config symlink: %w", err)
+	}
+
+	return nil
+}
+
+// configureOneAgent renders the same configuration as k8s-init, except for the container.conf:
+// it only contains Kubernetes pod and container attributes, and the platform attributes are instance-specific,
+// while the rendered configuration is shared by all instances, so they are part of the metadata enrichment instead.
+func configureOneAgent(log logr.Logger, agentFolder, workDir string) error {
+	agentInstallPath, err := getInstallPath()
+	if err != nil {
+		return err
+	}
+
+	if err := preload.Configure(log, workDir, agentInstallPath); err != nil {
+		log.Info("failed to configure the ld.so.preload", "config-directory", workDir)
+
+		return err
+	}
+
+	if err := pmc.Configure(log, inputDir, agentFolder, workDir, agentInstallPath); err != nil {
+		log.Info("failed to configure the ruxitagentproc.conf", "config-directory", workDir)
+
+		return err
+	}
+
+	if err := curl.Configure(log, inputDir, workDir); err != nil {
+		log.Info("failed to configure the curl options", "config-directory", workDir)
+
+		return err
+	}
+
+	if err := ca.Configure(log, inputDir, workDir, systemCAFile); err != nil {
+		log.Info("failed to configure the CAs", "config-directory", workDir)
+
+		return err
+	}
+
+	if err := pgc.Configure(log, inputDir, workDir); err != nil {
+		log.Info("failed to configure declarative.cbor", "config-directory", workDir)
+
+		return err
+	}
+
+	return nil
+}
+
+// getVersionedConfigFolder returns the path of the symlink to the configuration of the agent version, which the applications use.
+func getVersionedConfigFolder(agentVersion string) string {
+	return filepath.Join(configDir, agentVersion)
+}
+
+// getRenderedConfigFolder returns the folder the configuration of the agent version is rendered into with the current inputs.
+func getRenderedConfigFolder(agentVersion string) (string, error) {
+	current, err := getConfigFingerprint(agentVersion)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, agentVersion+"-"+current[:fingerprintSuffixLength]), nil
+}
+
+// getConfigFingerprint returns the fingerprint of all inputs of the configuration of the agent version.
+func getConfigFingerprint(agentVersion string) (string, error) {
+	agentInstallPath, err := getInstallPath()
+	if err != nil {
+		return "", err
+	}
+
+	values := []string{
+		"bootstrapper-version=" + version.Version,
+		"agent-version=" + agentVersion,
+		InstallPathFlag + "=" + agentInstallPath,
+		SystemCAFileFlag + "=" + systemCAFile,
+	}
+
+	if systemCAFile != "" {
+		// the system CA file is not in the input directory, so its content is part of the values, a missing file is reported by the configuration
+		systemCA, _ := os.ReadFile(systemCAFile)
+		hash := sha256.Sum256(systemCA)
+
+		values = append(values, "system-ca-sha256="+hex.EncodeToString(hash[:]))
+	}
+
+	current, err := fingerprint.Compute(inputDir, values...)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute the fingerprint of the configuration inputs: %w", err)
+	}
+
+	return current, nil
+}
+
+// getInstallPath returns the absolute path the application loads the CodeModule from.
+func getInstallPath() (string, error) {
+	if installPath != "" {
+		return installPath, nil
+	}
+
+	activeLink, err := filepath.Abs(filepath.Join(targetFolder, deployment.ActiveLinkPath))
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the install path: %w", err)
+	}
+
+	return activeLink, nil
+}
@@ -0,0 +1,143 @@
+package serverless
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/ca"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/curl"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/preload"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	fsutils "github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigure(t *testing.T) {
+	const agentVersion = "1.327.30.20251107-111521"
+
+	t.Run("configuration is rendered into the versioned config folder", func(t *testing.T) {
+		setupServerlessLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+		require.NoError(t, fsutils.CreateFile(pmc.GetSourceRuxitAgentProcFilePath(sourceDir), "[general]\nkey value\n"))
+
+		inputDir := t.TempDir()
+		require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, pmc.InputFileName), `{"properties":[{"section":"general","key":"other","value":"value"}]}`))
+		require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, curl.InputFileName), "123"))
+		require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, ca.TrustedCertsInputFile), tests.NewValidCertificatePEM(t, "trusted")))
+
+		targetDir := t.TempDir()
+		configDir := filepath.Join(targetDir, "config")
+
+		cmd := New()
+		cmd.SetArgs([]string{"--keep-alive=false", "--source", sourceDir, "--target", targetDir, "--work", t.TempDir(),
+			"--input-directory", inputDir, "--config-directory", configDir})
+		require.NoError(t, cmd.Execute())
+
+		versionedConfigDir := filepath.Join(configDir, agentVersion)
+		assert.FileExists(t, filepath.Join(versionedConfigDir, preload.ConfigPath))
+		assert.FileExists(t, pmc.GetDestinationRuxitAgentProcFilePath(versionedConfigDir))
+		assert.FileExists(t, filepath.Join(versionedConfigDir, curl.ConfigPath))
+		assert.FileExists(t, filepath.Join(versionedConfigDir, ca.ConfigBasePath, ca.CertsFileName))
+
+		preloadContent, err := os.ReadFile(filepath.Join(versionedConfigDir, preload.ConfigPath))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(targetDir, deployment.ActiveLinkPath, preload.LibAgentProcPath), string(preloadContent))
+
+		versionedConfigDirInfo, err := os.Stat(versionedConfigDir)
+		require.NoError(t, err)
+		assert.Equal(t, configDirPerm, versionedConfigDirInfo.Mode().Perm())
+
+		// no work folder is left behind, only the symlink and the rendered folder
+		entries, err := os.ReadDir(configDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("configuration is rendered again when the inputs change", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		inputDir := t.TempDir()
+		require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, curl.InputFileName), "123"))
+
+		targetDir := t.TempDir()
+		configDir := filepath.Join(targetDir, "config")
+		args := []string{"--keep-alive=false", "--source", sourceDir, "--target", targetDir, "--work", t.TempDir(),
+			"--input-directory", inputDir, "--config-directory", configDir}
+
+		cmd := New()
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+
+		previousConfigDir, err := os.Readlink(filepath.Join(configDir, agentVersion))
+		require.NoError(t, err)
+
+		// unchanged inputs ==> nothing to do
+		cmd = New()
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+		tests.RequireLogMessage(t, logsObserver, "OneAgent is already deployed")
+
+		require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, curl.InputFileName), "456"))
+
+		cmd = New()
+		cmd.SetArgs(args)
+		require.NoError(t, cmd.Execute())
+
+		currentConfigDir, err := os.Readlink(filepath.Join(configDir, agentVersion))
+		require.NoError(t, err)
+		assert.NotEqual(t, previousConfigDir, currentConfigDir)
+
+		curlOptions, err := os.ReadFile(filepath.Join(configDir, agentVersion, curl.ConfigPath))
+		require.NoError(t, err)
+		assert.Contains(t, string(curlOptions), "456")
+
+		// the previous configuration may still be in use by the applications
+		assert.DirExists(t, filepath.Join(configDir, previousConfigDir))
+	})
+
+	t.Run("missing configuration of a deployed agent is rendered", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		configDir := filepath.Join(t.TempDir(), "config")
+
+		cmd := New()
+		cmd.SetArgs([]string{"--keep-alive=false", "--source", sourceDir, "--target", targetDir, "--work", t.TempDir(),
+			"--input-directory", t.TempDir(), "--config-directory", configDir, "--install-path", "/opt/dynatrace/oneagent"})
+		require.NoError(t, cmd.Execute())
+
+		tests.RequireLogMessage(t, logsObserver, "finished oneagent configuration")
+		_, err := os.Stat(filepath.Join(configDir, agentVersion))
+		require.NoError(t, err)
+	})
+
+	t.Run("no config-directory ==> do nothing", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		cmd := New()
+		cmd.SetArgs([]string{"--keep-alive=false", "--source", sourceDir, "--target", targetDir, "--input-directory", t.TempDir()})
+		require.NoError(t, cmd.Execute())
+
+		tests.RequireLogMessage(t, logsObserver, "OneAgent is already deployed")
+	})
+}
@@ -0,0 +1,64 @@
+package serverless
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/enrichment/metadata"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
+)
+
+// instancesFolder is the folder in the config directory which contains a subfolder per instance.
+const instancesFolder = "instances"
+
+// enrichWithMetadata creates the metadata-enrichment files for this instance in <config-directory>/instances/<instance-id>.
+// The attributes and the instance id are supplied by the detected platform, nothing is created if the platform has no attributes.
+// The files are specific to the instance, so they are created by every instance independent of the deployment lock.
+func enrichWithMetadata() error {
+	if configDir == "" {
+		log.Debug(logger, "no config-directory set, skipping metadata enrichment")
+
+		return nil
+	}
+
+	attributes, err := detectedPlatform.Attributes()
+	if err != nil {
+		return fmt.Errorf("failed to determine the attributes of the platform: %w", err)
+	}
+
+	if len(attributes) == 0 {
+		log.Debug(logger, "no attributes for the platform, skipping metadata enrichment", "platform", detectedPlatform.Name())
+
+		return nil
+	}
+
+	instanceID, err := detectedPlatform.InstanceID()
+	if err != nil {
+		return fmt.Errorf("failed to determine the instance id: %w", err)
+	}
+
+	instanceDir, err := getInstanceConfigFolder(instanceID)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("starting metadata enrichment", "config-directory", instanceDir)
+
+	if err := metadata.ConfigureFromMap(logger, instanceDir, attributes); err != nil {
+		return fmt.Errorf("failed to create the metadata-enrichment files: %w", err)
+	}
+
+	logger.Info("finished metadata enrichment", "config-directory", instanceDir)
+
+	return nil
+}
+
+// getInstanceConfigFolder returns the config folder of the instance with the given id.
+func getInstanceConfigFolder(instanceID string) (string, error) {
+	if instanceID == "" || instanceID == "." || instanceID == ".." || strings.ContainsAny(instanceID, `/\`) {
+		return "", fmt.Errorf("invalid instance id %q", instanceID)
+	}
+
+	return filepath.Join(configDir, instancesFolder, instanceID), nil
+}
@@ -0,0 +1,76 @@
+package serverless
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/serverless/platform"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/enrichment/metadata"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichWithMetadata(t *testing.T) {
+	const agentVersion = "1.327.30.20251107-111521"
+
+	t.Run("Azure App Service ==> metadata files per instance", func(t *testing.T) {
+		setupServerlessLogger()
+
+		t.Setenv(platform.AzureSiteNameEnv, "my-site")
+		t.Setenv(platform.AzureResourceGroupEnv, "my-rg")
+		t.Setenv(platform.AzureOwnerNameEnv, "00000000-0000-0000-0000-000000000000+my-rg-WestEuropewebspace")
+		t.Setenv(platform.AzureInstanceIDEnv, "abcdef0123456789")
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		configDir := filepath.Join(t.TempDir(), "config")
+
+		cmd := New()
+		cmd.SetArgs([]string{"--keep-alive=false", "--source", sourceDir, "--target", targetDir, "--config-directory", configDir})
+		require.NoError(t, cmd.Execute())
+
+		instanceDir := filepath.Join(configDir, instancesFolder, "abcdef0123456789")
+
+		jsonContent, err := os.ReadFile(filepath.Join(instanceDir, metadata.JSONFilePath))
+		require.NoError(t, err)
+		assert.Contains(t, string(jsonContent), `"cloud.platform":"azure_app_service"`)
+		assert.Contains(t, string(jsonContent), `"service.name":"my-site"`)
+		assert.Contains(t, string(jsonContent), `"cloud.resource_id":"/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/my-rg/providers/Microsoft.Web/sites/my-site"`)
+
+		propsContent, err := os.ReadFile(filepath.Join(instanceDir, metadata.PropertiesFilePath))
+		require.NoError(t, err)
+		assert.Contains(t, string(propsContent), "service.instance.id=abcdef0123456789\n")
+	})
+
+	t.Run("generic platform ==> do nothing", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		t.Setenv(platform.AzureSiteNameEnv, "")
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		configDir := filepath.Join(t.TempDir(), "config")
+
+		cmd := New()
+		cmd.SetArgs([]string{"--keep-alive=false", "--source", sourceDir, "--target", targetDir, "--config-directory", configDir})
+		require.NoError(t, cmd.Execute())
+
+		tests.RequireLogMessage(t, logsObserver, "OneAgent is already deployed")
+		assert.NoDirExists(t, filepath.Join(configDir, instancesFolder))
+	})
+
+	t.Run("invalid instance id ==> error", func(t *testing.T) {
+		_, err := getInstanceConfigFolder("../other")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,105 @@
+package platform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/structs"
+)
+
+// Environment variables set by Azure App Service for every instance of an app.
+const (
+	AzureSiteNameEnv      = "WEBSITE_SITE_NAME"
+	AzureResourceGroupEnv = "WEBSITE_RESOURCE_GROUP"
+	AzureOwnerNameEnv     = "WEBSITE_OWNER_NAME"
+	AzureInstanceIDEnv    = "WEBSITE_INSTANCE_ID"
+	AzureSlotNameEnv      = "WEBSITE_SLOT_NAME"
+	AzureRegionNameEnv    = "REGION_NAME"
+)
+
+const (
+	// the /home folder is the persistent storage shared by all instances of an app
+	azureTargetFolder = "/home/dynatrace"
+	azureWorkFolder   = "/home/dynatrace/oneagent/work"
+
+	// the WEBSITE_OWNER_NAME has the format <subscription-id>+<resource-group>-<region>webspace
+	azureOwnerNameSeparator = "+"
+)
+
+// AzureAppService is detected by the WEBSITE_SITE_NAME environment variable.
+type AzureAppService struct{}
+
+var _ Provider = AzureAppService{}
+
+type azureAttributes struct {
+	Provider      string `json:"cloud.provider,omitempty"`
+	Platform      string `json:"cloud.platform,omitempty"`
+	Region        string `json:"cloud.region,omitempty"`
+	AccountID     string `json:"cloud.account.id,omitempty"`
+	ResourceID    string `json:"cloud.resource_id,omitempty"`
+	ResourceGroup string `json:"azure.resource.group,omitempty"`
+	ServiceName   string `json:"service.name,omitempty"`
+	InstanceID    string `json:"service.instance.id,omitempty"`
+	HostID        string `json:"host.id,omitempty"`
+	Environment   string `json:"deployment.environment,omitempty"`
+}
+
+func (AzureAppService) Name() string {
+	return "azure-app-service"
+}
+
+func (AzureAppService) Detect(env Env) bool {
+	return env.Getenv(AzureSiteNameEnv) != ""
+}
+
+func (AzureAppService) DefaultPaths() Paths {
+	return Paths{
+		Source: defaultSourceFolder,
+		Target: azureTargetFolder,
+		Work:   azureWorkFolder,
+	}
+}
+
+func (AzureAppService) InstanceID(env Env) (string, error) {
+	if instanceID := env.Getenv(AzureInstanceIDEnv); instanceID != "" {
+		return instanceID, nil
+	}
+
+	return hostnameInstanceID(env)
+}
+
+// Attributes maps the environment variables of Azure App Service into cloud and service attributes.
+// Attributes whose environment variables are not set are left out.
+func (AzureAppService) Attributes(env Env) (map[string]string, error) {
+	siteName := env.Getenv(AzureSiteNameEnv)
+	resourceGroup := env.Getenv(AzureResourceGroupEnv)
+	subscriptionID := parseAzureSubscriptionID(env.Getenv(AzureOwnerNameEnv))
+
+	// the WEBSITE_HOSTNAME is the same for all instances of an app, only the instance id identifies the host
+	attr := azureAttributes{
+		Provider:      "azure",
+		Platform:      "azure_app_service",
+		Region:        env.Getenv(AzureRegionNameEnv),
+		AccountID:     subscriptionID,
+		ResourceGroup: resourceGroup,
+		ServiceName:   siteName,
+		InstanceID:    env.Getenv(AzureInstanceIDEnv),
+		HostID:        env.Getenv(AzureInstanceIDEnv),
+		Environment:   env.Getenv(AzureSlotNameEnv),
+	}
+
+	if siteName != "" && resourceGroup != "" && subscriptionID != "" {
+		attr.ResourceID = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Web/sites/%s", subscriptionID, resourceGroup, siteName)
+	}
+
+	return structs.ToMap(attr)
+}
+
+func parseAzureSubscriptionID(ownerName string) string {
+	subscriptionID, _, found := strings.Cut(ownerName, azureOwnerNameSeparator)
+	if !found {
+		return ""
+	}
+
+	return subscriptionID
+}
@@ -0,0 +1,68 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAzureAppService(t *testing.T) {
+	t.Run("all environment variables set", func(t *testing.T) {
+		env := testEnv(map[string]string{
+			AzureSiteNameEnv:      "my-site",
+			AzureResourceGroupEnv: "my-rg",
+			AzureOwnerNameEnv:     "00000000-0000-0000-0000-000000000000+my-rg-WestEuropewebspace",
+			AzureInstanceIDEnv:    "abcdef0123456789",
+			AzureSlotNameEnv:      "staging",
+			AzureRegionNameEnv:    "West Europe",
+		}, nil)
+
+		require.True(t, AzureAppService{}.Detect(env))
+
+		expected := map[string]string{
+			"cloud.provider":         "azure",
+			"cloud.platform":         "azure_app_service",
+			"cloud.region":           "West Europe",
+			"cloud.account.id":       "00000000-0000-0000-0000-000000000000",
+			"cloud.resource_id":      "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/my-rg/providers/Microsoft.Web/sites/my-site",
+			"azure.resource.group":   "my-rg",
+			"service.name":           "my-site",
+			"service.instance.id":    "abcdef0123456789",
+			"host.id":                "abcdef0123456789",
+			"deployment.environment": "staging",
+		}
+
+		attributes, err := AzureAppService{}.Attributes(env)
+		require.NoError(t, err)
+		assert.Equal(t, expected, attributes)
+
+		instanceID, err := AzureAppService{}.InstanceID(env)
+		require.NoError(t, err)
+		assert.Equal(t, "abcdef0123456789", instanceID)
+	})
+
+	t.Run("missing owner name ==> no account and resource id", func(t *testing.T) {
+		env := testEnv(map[string]string{
+			AzureSiteNameEnv:      "my-site",
+			AzureResourceGroupEnv: "my-rg",
+		}, nil)
+
+		attributes, err := AzureAppService{}.Attributes(env)
+		require.NoError(t, err)
+
+		assert.Equal(t, "my-site", attributes["service.name"])
+		assert.NotContains(t, attributes, "cloud.account.id")
+		assert.NotContains(t, attributes, "cloud.resource_id")
+	})
+
+	t.Run("missing instance id ==> hostname", func(t *testing.T) {
+		instanceID, err := AzureAppService{}.InstanceID(testEnv(map[string]string{AzureSiteNameEnv: "my-site"}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, testHostname, instanceID)
+	})
+
+	t.Run("not on Azure App Service", func(t *testing.T) {
+		assert.False(t, AzureAppService{}.Detect(testEnv(nil, nil)))
+	})
+}
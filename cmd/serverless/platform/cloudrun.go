@@ -0,0 +1,87 @@
+package platform
+
+import (
+	"fmt"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/structs"
+)
+
+// Environment variables set by Google Cloud Run for services and jobs.
+const (
+	CloudRunServiceEnv   = "K_SERVICE"
+	CloudRunRevisionEnv  = "K_REVISION"
+	CloudRunJobEnv       = "CLOUD_RUN_JOB"
+	CloudRunExecutionEnv = "CLOUD_RUN_EXECUTION"
+	CloudRunTaskIndexEnv = "CLOUD_RUN_TASK_INDEX"
+)
+
+const (
+	cloudRunTargetFolder = "/mnt/dynatrace"
+	cloudRunWorkFolder   = "/mnt/dynatrace/oneagent/work"
+)
+
+// CloudRun is detected by the K_SERVICE (services) or CLOUD_RUN_JOB (jobs) environment variables.
+type CloudRun struct{}
+
+var _ Provider = CloudRun{}
+
+type cloudRunAttributes struct {
+	Provider       string `json:"cloud.provider,omitempty"`
+	Platform       string `json:"cloud.platform,omitempty"`
+	ServiceName    string `json:"service.name,omitempty"`
+	ServiceVersion string `json:"service.version,omitempty"`
+	FaaSName       string `json:"faas.name,omitempty"`
+	FaaSVersion    string `json:"faas.version,omitempty"`
+	JobExecution   string `json:"gcp.cloud_run.job.execution,omitempty"`
+	JobTaskIndex   string `json:"gcp.cloud_run.job.task_index,omitempty"`
+}
+
+func (CloudRun) Name() string {
+	return "cloud-run"
+}
+
+func (CloudRun) Detect(env Env) bool {
+	return env.Getenv(CloudRunServiceEnv) != "" || env.Getenv(CloudRunJobEnv) != ""
+}
+
+func (CloudRun) DefaultPaths() Paths {
+	return Paths{
+		Source: defaultSourceFolder,
+		Target: cloudRunTargetFolder,
+		Work:   cloudRunWorkFolder,
+	}
+}
+
+// InstanceID returns <execution>-<task-index> for jobs. Services don't expose the instance id
+// via the environment (only via the metadata server), so the hostname is used.
+func (CloudRun) InstanceID(env Env) (string, error) {
+	execution := env.Getenv(CloudRunExecutionEnv)
+	taskIndex := env.Getenv(CloudRunTaskIndexEnv)
+
+	if execution != "" && taskIndex != "" {
+		return fmt.Sprintf("%s-%s", execution, taskIndex), nil
+	}
+
+	return hostnameInstanceID(env)
+}
+
+func (CloudRun) Attributes(env Env) (map[string]string, error) {
+	attr := cloudRunAttributes{
+		Provider: "gcp",
+		Platform: "gcp_cloud_run",
+	}
+
+	if job := env.Getenv(CloudRunJobEnv); job != "" {
+		attr.ServiceName = job
+		attr.FaaSName = job
+		attr.JobExecution = env.Getenv(CloudRunExecutionEnv)
+		attr.JobTaskIndex = env.Getenv(CloudRunTaskIndexEnv)
+	} else {
+		attr.ServiceName = env.Getenv(CloudRunServiceEnv)
+		attr.ServiceVersion = env.Getenv(CloudRunRevisionEnv)
+		attr.FaaSName = attr.ServiceName
+		attr.FaaSVersion = attr.ServiceVersion
+	}
+
+	return structs.ToMap(attr)
+}
@@ -0,0 +1,56 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudRun(t *testing.T) {
+	t.Run("service", func(t *testing.T) {
+		env := testEnv(map[string]string{
+			CloudRunServiceEnv:  "my-service",
+			CloudRunRevisionEnv: "my-service-00001-abc",
+		}, nil)
+
+		require.True(t, CloudRun{}.Detect(env))
+
+		expected := map[string]string{
+			"cloud.provider":  "gcp",
+			"cloud.platform":  "gcp_cloud_run",
+			"service.name":    "my-service",
+			"service.version": "my-service-00001-abc",
+			"faas.name":       "my-service",
+			"faas.version":    "my-service-00001-abc",
+		}
+
+		attributes, err := CloudRun{}.Attributes(env)
+		require.NoError(t, err)
+		assert.Equal(t, expected, attributes)
+
+		instanceID, err := CloudRun{}.InstanceID(env)
+		require.NoError(t, err)
+		assert.Equal(t, testHostname, instanceID)
+	})
+
+	t.Run("job", func(t *testing.T) {
+		env := testEnv(map[string]string{
+			CloudRunJobEnv:       "my-job",
+			CloudRunExecutionEnv: "my-job-abcde",
+			CloudRunTaskIndexEnv: "3",
+		}, nil)
+
+		require.True(t, CloudRun{}.Detect(env))
+
+		attributes, err := CloudRun{}.Attributes(env)
+		require.NoError(t, err)
+		assert.Equal(t, "my-job", attributes["faas.name"])
+		assert.Equal(t, "my-job-abcde", attributes["gcp.cloud_run.job.execution"])
+		assert.Equal(t, "3", attributes["gcp.cloud_run.job.task_index"])
+
+		instanceID, err := CloudRun{}.InstanceID(env)
+		require.NoError(t, err)
+		assert.Equal(t, "my-job-abcde-3", instanceID)
+	})
+}
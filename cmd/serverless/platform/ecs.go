@@ -0,0 +1,100 @@
+package platform
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/structs"
+)
+
+// Environment variables set by AWS ECS, on Fargate and EC2.
+const (
+	ECSMetadataURIEnv  = "ECS_CONTAINER_METADATA_URI_V4"
+	ECSMetadataFileEnv = "ECS_CONTAINER_METADATA_FILE"
+	AWSExecutionEnv    = "AWS_EXECUTION_ENV"
+	AWSRegionEnv       = "AWS_REGION"
+)
+
+const (
+	ecsTargetFolder = "/mnt/dynatrace"
+	ecsWorkFolder   = "/mnt/dynatrace/oneagent/work"
+
+	// the AWS_EXECUTION_ENV is either AWS_ECS_FARGATE or AWS_ECS_EC2
+	ecsExecutionEnvPrefix = "AWS_ECS_"
+)
+
+// ECS is detected by the ECS_CONTAINER_METADATA_URI_V4 or AWS_EXECUTION_ENV environment variables.
+type ECS struct{}
+
+var _ Provider = ECS{}
+
+type ecsAttributes struct {
+	Provider   string `json:"cloud.provider,omitempty"`
+	Platform   string `json:"cloud.platform,omitempty"`
+	Region     string `json:"cloud.region,omitempty"`
+	LaunchType string `json:"aws.ecs.launchtype,omitempty"`
+	ClusterARN string `json:"aws.ecs.cluster.arn,omitempty"`
+	TaskARN    string `json:"aws.ecs.task.arn,omitempty"`
+	Container  string `json:"container.name,omitempty"`
+}
+
+// ecsMetadataFile is the content of the local container metadata file, only available if enabled on EC2.
+type ecsMetadataFile struct {
+	Cluster       string `json:"Cluster"`
+	TaskARN       string `json:"TaskARN"`
+	ContainerName string `json:"ContainerName"`
+}
+
+func (ECS) Name() string {
+	return "aws-ecs"
+}
+
+func (ECS) Detect(env Env) bool {
+	return env.Getenv(ECSMetadataURIEnv) != "" || strings.HasPrefix(env.Getenv(AWSExecutionEnv), ecsExecutionEnvPrefix)
+}
+
+func (ECS) DefaultPaths() Paths {
+	return Paths{
+		Source: defaultSourceFolder,
+		Target: ecsTargetFolder,
+		Work:   ecsWorkFolder,
+	}
+}
+
+// InstanceID returns the last segment of the container metadata URI, which is unique per task and container.
+func (ECS) InstanceID(env Env) (string, error) {
+	if metadataURI := env.Getenv(ECSMetadataURIEnv); metadataURI != "" {
+		return path.Base(metadataURI), nil
+	}
+
+	return hostnameInstanceID(env)
+}
+
+func (ECS) Attributes(env Env) (map[string]string, error) {
+	attr := ecsAttributes{
+		Provider:   "aws",
+		Platform:   "aws_ecs",
+		Region:     env.Getenv(AWSRegionEnv),
+		LaunchType: strings.ToLower(strings.TrimPrefix(env.Getenv(AWSExecutionEnv), ecsExecutionEnvPrefix)),
+	}
+
+	if metadataFile := env.Getenv(ECSMetadataFileEnv); metadataFile != "" {
+		raw, err := env.ReadFile(metadataFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the ECS container metadata file: %w", err)
+		}
+
+		var metadata ecsMetadataFile
+		if err := json.Unmarshal(raw, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse the ECS container metadata file: %w", err)
+		}
+
+		attr.ClusterARN = metadata.Cluster
+		attr.TaskARN = metadata.TaskARN
+		attr.Container = metadata.ContainerName
+	}
+
+	return structs.ToMap(attr)
+}
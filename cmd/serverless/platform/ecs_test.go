@@ -0,0 +1,67 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECS(t *testing.T) {
+	t.Run("Fargate", func(t *testing.T) {
+		env := testEnv(map[string]string{
+			ECSMetadataURIEnv: "http://169.254.170.2/v4/a8585d4aa6f2407d9b1f6c4f2d0c1b2e-3812365926",
+			AWSExecutionEnv:   "AWS_ECS_FARGATE",
+			AWSRegionEnv:      "eu-west-1",
+		}, nil)
+
+		require.True(t, ECS{}.Detect(env))
+
+		expected := map[string]string{
+			"cloud.provider":     "aws",
+			"cloud.platform":     "aws_ecs",
+			"cloud.region":       "eu-west-1",
+			"aws.ecs.launchtype": "fargate",
+		}
+
+		attributes, err := ECS{}.Attributes(env)
+		require.NoError(t, err)
+		assert.Equal(t, expected, attributes)
+
+		instanceID, err := ECS{}.InstanceID(env)
+		require.NoError(t, err)
+		assert.Equal(t, "a8585d4aa6f2407d9b1f6c4f2d0c1b2e-3812365926", instanceID)
+	})
+
+	t.Run("EC2 with metadata file", func(t *testing.T) {
+		env := testEnv(map[string]string{
+			AWSExecutionEnv:    "AWS_ECS_EC2",
+			ECSMetadataFileEnv: "/opt/ecs/metadata/abc/ecs-container-metadata.json",
+		}, map[string]string{
+			"/opt/ecs/metadata/abc/ecs-container-metadata.json": `{"Cluster":"my-cluster","TaskARN":"arn:aws:ecs:eu-west-1:123:task/my-cluster/abc","ContainerName":"app"}`,
+		})
+
+		require.True(t, ECS{}.Detect(env))
+
+		attributes, err := ECS{}.Attributes(env)
+		require.NoError(t, err)
+		assert.Equal(t, "ec2", attributes["aws.ecs.launchtype"])
+		assert.Equal(t, "my-cluster", attributes["aws.ecs.cluster.arn"])
+		assert.Equal(t, "arn:aws:ecs:eu-west-1:123:task/my-cluster/abc", attributes["aws.ecs.task.arn"])
+		assert.Equal(t, "app", attributes["container.name"])
+
+		instanceID, err := ECS{}.InstanceID(env)
+		require.NoError(t, err)
+		assert.Equal(t, testHostname, instanceID)
+	})
+
+	t.Run("missing metadata file ==> error", func(t *testing.T) {
+		env := testEnv(map[string]string{
+			AWSExecutionEnv:    "AWS_ECS_EC2",
+			ECSMetadataFileEnv: "/does/not/exist.json",
+		}, nil)
+
+		_, err := ECS{}.Attributes(env)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,34 @@
+package platform
+
+const (
+	defaultSourceFolder = "/opt/dynatrace/oneagent"
+	defaultWorkFolder   = "/home/dynatrace/oneagent/work"
+)
+
+// Generic is used if no specific platform is detected. It has no default target and no attributes.
+type Generic struct{}
+
+var _ Provider = Generic{}
+
+func (Generic) Name() string {
+	return "generic"
+}
+
+func (Generic) Detect(_ Env) bool {
+	return true
+}
+
+func (Generic) DefaultPaths() Paths {
+	return Paths{
+		Source: defaultSourceFolder,
+		Work:   defaultWorkFolder,
+	}
+}
+
+func (Generic) InstanceID(env Env) (string, error) {
+	return hostnameInstanceID(env)
+}
+
+func (Generic) Attributes(_ Env) (map[string]string, error) {
+	return map[string]string{}, nil
+}
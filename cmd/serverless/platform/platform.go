@@ -0,0 +1,88 @@
+package platform
+
+import (
+	"os"
+)
+
+// Paths are the default paths used for the deployment on a platform.
+type Paths struct {
+	Source string
+	Target string
+	Work   string
+}
+
+// Provider describes a serverless platform the Bootstrapper can run on.
+type Provider interface {
+	// Name returns the name of the platform, as used in logs.
+	Name() string
+	// Detect returns true if the process runs on the platform.
+	Detect(env Env) bool
+	// DefaultPaths returns the default paths for the deployment, an empty path means there is no default.
+	DefaultPaths() Paths
+	// InstanceID returns an id which is unique for every instance of the application.
+	InstanceID(env Env) (string, error)
+	// Attributes returns the attributes describing the application and the instance on the platform.
+	Attributes(env Env) (map[string]string, error)
+}
+
+// Env gives access to the environment variables and local files used to detect the platform.
+type Env struct {
+	Getenv   func(key string) string
+	ReadFile func(name string) ([]byte, error)
+	Hostname func() (string, error)
+}
+
+// OSEnv returns the Env of the current process.
+func OSEnv() Env {
+	return Env{
+		Getenv:   os.Getenv,
+		ReadFile: os.ReadFile,
+		Hostname: os.Hostname,
+	}
+}
+
+// Providers are the supported platforms, in the order they are detected.
+// The Generic platform is used if none of them is detected.
+var Providers = []Provider{
+	AzureAppService{},
+	CloudRun{},
+	ECS{},
+}
+
+// Platform is a detected Provider together with the Env it was detected in.
+type Platform struct {
+	provider Provider
+	env      Env
+}
+
+// Detect returns the first of the given providers which is detected in the Env, or the Generic platform.
+func Detect(env Env, providers ...Provider) Platform {
+	for _, provider := range providers {
+		if provider.Detect(env) {
+			return Platform{provider: provider, env: env}
+		}
+	}
+
+	return Platform{provider: Generic{}, env: env}
+}
+
+func (p Platform) Name() string {
+	return p.provider.Name()
+}
+
+func (p Platform) DefaultPaths() Paths {
+	return p.provider.DefaultPaths()
+}
+
+func (p Platform) InstanceID() (string, error) {
+	return p.provider.InstanceID(p.env)
+}
+
+func (p Platform) Attributes() (map[string]string, error) {
+	return p.provider.Attributes(p.env)
+}
+
+// hostnameInstanceID is the fallback instance id for platforms which don't expose one.
+func hostnameInstanceID(env Env) (string, error) {
+	return env.Hostname()
+}
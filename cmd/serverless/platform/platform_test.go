@@ -0,0 +1,83 @@
+package platform
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHostname = "test-host"
+
+// testEnv returns an Env with the given environment variables and files.
+func testEnv(vars map[string]string, files map[string]string) Env {
+	return Env{
+		Getenv: func(key string) string {
+			return vars[key]
+		},
+		ReadFile: func(name string) ([]byte, error) {
+			content, ok := files[name]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+
+			return []byte(content), nil
+		},
+		Hostname: func() (string, error) {
+			return testHostname, nil
+		},
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Run("Azure App Service", func(t *testing.T) {
+		platform := Detect(testEnv(map[string]string{AzureSiteNameEnv: "my-site"}, nil), Providers...)
+		assert.Equal(t, "azure-app-service", platform.Name())
+	})
+
+	t.Run("Cloud Run", func(t *testing.T) {
+		platform := Detect(testEnv(map[string]string{CloudRunServiceEnv: "my-service"}, nil), Providers...)
+		assert.Equal(t, "cloud-run", platform.Name())
+	})
+
+	t.Run("AWS ECS", func(t *testing.T) {
+		platform := Detect(testEnv(map[string]string{AWSExecutionEnv: "AWS_ECS_FARGATE"}, nil), Providers...)
+		assert.Equal(t, "aws-ecs", platform.Name())
+	})
+
+	t.Run("unknown platform ==> generic", func(t *testing.T) {
+		platform := Detect(testEnv(map[string]string{AWSExecutionEnv: "AWS_Lambda_go"}, nil), Providers...)
+		assert.Equal(t, "generic", platform.Name())
+
+		paths := platform.DefaultPaths()
+		assert.Empty(t, paths.Target)
+		assert.Equal(t, "/opt/dynatrace/oneagent", paths.Source)
+		assert.Equal(t, "/home/dynatrace/oneagent/work", paths.Work)
+
+		instanceID, err := platform.InstanceID()
+		require.NoError(t, err)
+		assert.Equal(t, testHostname, instanceID)
+
+		attributes, err := platform.Attributes()
+		require.NoError(t, err)
+		assert.Empty(t, attributes)
+	})
+
+	t.Run("first detected provider wins", func(t *testing.T) {
+		env := testEnv(map[string]string{AzureSiteNameEnv: "my-site", CloudRunServiceEnv: "my-service"}, nil)
+
+		assert.Equal(t, "cloud-run", Detect(env, CloudRun{}, AzureAppService{}).Name())
+	})
+
+	t.Run("hostname error is returned", func(t *testing.T) {
+		env := testEnv(nil, nil)
+		env.Hostname = func() (string, error) {
+			return "", errors.New("some error")
+		}
+
+		_, err := Detect(env).InstanceID()
+		require.Error(t, err)
+	})
+}
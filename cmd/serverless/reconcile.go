@@ -0,0 +1,99 @@
+package serverless
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
+)
+
+// deployedVersion is the OneAgent version the reconciliation keeps deployed, it is only changed by the source watcher.
+var deployedVersion atomic.Pointer[string]
+
+func setDeployedVersion(version string) {
+	deployedVersion.Store(&version)
+}
+
+func getDeployedVersion() string {
+	if version := deployedVersion.Load(); version != nil {
+		return *version
+	}
+
+	return ""
+}
+
+// reconcileDeployment blocks until the context is cancelled, and repairs the OneAgent deployment whenever it drifts,
+// e.g. the `active` symlink or the versioned OneAgent folder was removed from the shared storage.
+// The deployment status is checked on every change of the `active` symlink and every reconcile interval.
+// Only the deployed OneAgent version is repaired, a new version in the source is left to the source watcher (see watchSourceVersion).
+func reconcileDeployment(ctx context.Context) {
+	log.Debug(logger, "Reconciling the OneAgent deployment", "reconcile interval", reconcileInterval.String())
+
+	waiter := deployment.NewActiveLinkWaiter(logger, targetFolder, reconcileInterval)
+	defer waiter.Close()
+
+	var (
+		lastErr       error
+		drifted       bool
+		sourceChanged bool
+	)
+
+	for {
+		if err := waiter.Wait(ctx); err != nil {
+			return
+		}
+
+		result := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology)
+		switch {
+		case result.Error != nil:
+			// Log the deployment error only if it differs from the previous one to avoid log spam
+			if lastErr == nil || result.Error.Error() != lastErr.Error() {
+				logger.Error(result.Error, "failed to check OneAgent deployment status", "status", result.Status.String())
+				lastErr = result.Error
+			}
+		case result.AgentVersion != getDeployedVersion():
+			// the deployed version can't be repaired from the source anymore, and deploying the new one is an upgrade
+			if !sourceChanged {
+				logger.Info("The source contains another OneAgent version, it is not deployed by the reconciliation",
+					"OneAgent version", result.AgentVersion, "deployed OneAgent version", getDeployedVersion())
+			}
+
+			lastErr, sourceChanged = nil, true
+		case result.Status == deployment.Deployed && isAgentConfigured(result.AgentVersion):
+			if drifted {
+				logger.Info("OneAgent deployment drift has been resolved", "OneAgent version", result.AgentVersion)
+			}
+
+			lastErr, drifted, sourceChanged = nil, false, false
+		default:
+			if !drifted {
+				logger.Info("OneAgent deployment drift detected", "status", result.Status.String(), "OneAgent version", result.AgentVersion)
+			}
+
+			lastErr, drifted, sourceChanged = nil, true, false
+
+			repairDeployment(ctx)
+		}
+	}
+}
+
+// repairDeployment deploys OneAgent again, unless another instance holds the deployment lock and repairs it already.
+func repairDeployment(ctx context.Context) {
+	deployed, err := deployment.DeployOneAgent(ctx, logger, sourceFolder, targetFolder, workBaseFolder, technology, getDeploymentOptions()...)
+
+	switch {
+	case err != nil:
+		if ctx.Err() != nil {
+			logger.Info("OneAgent deployment repair was interrupted by a signal")
+
+			return
+		}
+
+		logger.Error(err, "failed to repair the OneAgent deployment")
+	case deployed:
+		logger.Info("OneAgent deployment has been repaired")
+	default:
+		log.Debug(logger, "OneAgent deployment is repaired by another instance")
+	}
+}
@@ -0,0 +1,101 @@
+package serverless
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileDeployment(t *testing.T) {
+	const agentVersion = "1.327.30.20251107-111521"
+
+	setup := func(t *testing.T) (string, string) {
+		t.Helper()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		cmd := New()
+		require.NoError(t, cmd.ParseFlags([]string{"--source", sourceDir, "--target", targetDir, "--work", t.TempDir(), "--reconcile-interval=100ms"}))
+		setDeployedVersion(agentVersion)
+
+		return sourceDir, targetDir
+	}
+
+	reconcile := func(t *testing.T) {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(t.Context())
+		finished := make(chan struct{})
+
+		go func() {
+			reconcileDeployment(ctx)
+			close(finished)
+		}()
+
+		t.Cleanup(func() {
+			cancel()
+			<-finished
+		})
+	}
+
+	t.Run("a removed `active` symlink is restored", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+		sourceDir, targetDir := setup(t)
+
+		reconcile(t)
+
+		require.NoError(t, os.Remove(filepath.Join(targetDir, deployment.ActiveLinkPath)))
+
+		require.Eventually(t, func() bool {
+			return len(logsObserver.FilterMessage("OneAgent deployment has been repaired")) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.Equal(t, deployment.Deployed, deployment.CheckAgentDeploymentStatus(sourceDir, targetDir, "").Status)
+		tests.RequireLogMessage(t, logsObserver, "OneAgent deployment drift detected", "status", "Deployment is not complete")
+	})
+
+	t.Run("a removed OneAgent folder is deployed again", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+		sourceDir, targetDir := setup(t)
+
+		reconcile(t)
+
+		require.NoError(t, os.RemoveAll(deployment.GetAgentFolder(targetDir, agentVersion)))
+
+		require.Eventually(t, func() bool {
+			return len(logsObserver.FilterMessage("OneAgent deployment drift has been resolved")) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.Equal(t, deployment.Deployed, deployment.CheckAgentDeploymentStatus(sourceDir, targetDir, "").Status)
+		tests.RequireLogMessage(t, logsObserver, "OneAgent deployment drift detected", "status", "Not deployed")
+	})
+
+	t.Run("a new source version is left to the source watcher", func(t *testing.T) {
+		const newVersion = "1.329.10.20251201-101010"
+
+		logsObserver := setupServerlessLogger()
+		sourceDir, targetDir := setup(t)
+
+		reconcile(t)
+
+		tests.SetupSourceDirectory(t, sourceDir, newVersion)
+		require.NoError(t, os.Remove(filepath.Join(targetDir, deployment.ActiveLinkPath)))
+
+		require.Eventually(t, func() bool {
+			return len(logsObserver.FilterMessage("The source contains another OneAgent version, it is not deployed by the reconciliation")) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.NoDirExists(t, deployment.GetAgentFolder(targetDir, newVersion))
+		require.Empty(t, logsObserver.FilterMessage("OneAgent deployment drift detected"))
+	})
+}
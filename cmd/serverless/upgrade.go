@@ -0,0 +1,93 @@
+package serverless
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
+)
+
+// watchSourceVersion blocks until the context is cancelled, and deploys the OneAgent version of the source
+// whenever its installer.version changes, e.g. because the source volume was updated in place.
+// A new version is only deployed once it is unchanged for the check interval, so a source which is still being updated is not copied.
+// The applications keep using the previously loaded OneAgent until they are restarted.
+func watchSourceVersion(ctx context.Context, currentVersion string) {
+	log.Debug(logger, "Watching the source for a new OneAgent version", "source", sourceFolder, "OneAgent version", currentVersion)
+
+	waiter := deployment.NewSourceVersionWaiter(logger, sourceFolder, checkInterval)
+	defer waiter.Close()
+
+	var (
+		lastErr        error
+		pendingVersion string
+		pendingSince   time.Time
+	)
+
+	for {
+		if err := waiter.Wait(ctx); err != nil {
+			return
+		}
+
+		result := deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology)
+
+		switch {
+		case result.Error != nil:
+			// Log the deployment error only if it differs from the previous one to avoid log spam
+			if lastErr == nil || result.Error.Error() != lastErr.Error() {
+				logger.Error(result.Error, "failed to check OneAgent deployment status", "status", result.Status.String())
+				lastErr = result.Error
+			}
+
+			continue
+		case result.AgentVersion == currentVersion:
+			// a regressed deployment of the current version is repaired by the reconciliation, if enabled
+			lastErr, pendingVersion = nil, ""
+
+			continue
+		case result.AgentVersion != pendingVersion:
+			logger.Info("Detected a new OneAgent version in the source", "OneAgent version", result.AgentVersion, "previous OneAgent version", currentVersion)
+
+			lastErr, pendingVersion, pendingSince = nil, result.AgentVersion, time.Now()
+
+			continue
+		case time.Since(pendingSince) < checkInterval:
+			continue
+		}
+
+		if upgradeDeployment(ctx, result) {
+			currentVersion, pendingVersion = result.AgentVersion, ""
+			setDeployedVersion(currentVersion)
+		}
+	}
+}
+
+// upgradeDeployment deploys the new OneAgent version into its own folder and switches the `active` symlink to it.
+// Returns true once the new version is deployed, either by this or another instance.
+func upgradeDeployment(ctx context.Context, result deployment.AgentDeploymentInfo) bool {
+	deployed := result.Status == deployment.Deployed && isAgentConfigured(result.AgentVersion)
+
+	if !deployed {
+		var err error
+
+		deployed, err = deployment.DeployOneAgent(ctx, logger, sourceFolder, targetFolder, workBaseFolder, technology, getDeploymentOptions()...)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error(err, "failed to deploy the new OneAgent version", "OneAgent version", result.AgentVersion)
+			}
+
+			return false
+		}
+
+		if !deployed {
+			// another instance holds the deployment lock, or has deployed the new version in the meantime
+			deployed = deployment.CheckAgentDeploymentStatus(sourceFolder, targetFolder, technology).Status == deployment.Deployed
+		}
+	}
+
+	if deployed {
+		logger.Info("The new OneAgent version is deployed, the application has to be restarted to load it", "OneAgent version", result.AgentVersion)
+	}
+
+	return deployed
+}
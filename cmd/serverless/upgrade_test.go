@@ -0,0 +1,67 @@
+package serverless
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchSourceVersion(t *testing.T) {
+	const (
+		agentVersion    = "1.327.30.20251107-111521"
+		newAgentVersion = "1.329.10.20251201-101010"
+	)
+
+	t.Run("a new version in the source is deployed into its own folder", func(t *testing.T) {
+		logsObserver := setupServerlessLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		cmd := New()
+		require.NoError(t, cmd.ParseFlags([]string{"--source", sourceDir, "--target", targetDir, "--work", t.TempDir(), "--check-interval=100ms", "--watch-source"}))
+
+		ctx, cancel := context.WithCancel(t.Context())
+		finished := make(chan struct{})
+
+		go func() {
+			watchSourceVersion(ctx, agentVersion)
+			close(finished)
+		}()
+
+		defer func() {
+			cancel()
+			<-finished
+		}()
+
+		// the source volume is updated in place
+		tests.SetupSourceDirectory(t, sourceDir, newAgentVersion)
+
+		require.Eventually(t, func() bool {
+			return len(logsObserver.FilterMessage("The new OneAgent version is deployed, the application has to be restarted to load it")) > 0
+		}, 10*time.Second, 10*time.Millisecond)
+
+		tests.RequireLogMessage(t, logsObserver, "Detected a new OneAgent version in the source", "OneAgent version", newAgentVersion)
+
+		result := deployment.CheckAgentDeploymentStatus(sourceDir, targetDir, "")
+		require.Equal(t, deployment.Deployed, result.Status)
+		require.Equal(t, newAgentVersion, result.AgentVersion)
+
+		activeLinkTarget, err := os.Readlink(filepath.Join(targetDir, deployment.ActiveLinkPath))
+		require.NoError(t, err)
+		assert.Equal(t, newAgentVersion, activeLinkTarget)
+
+		// the previous version is kept, it is still loaded by the application
+		assert.DirExists(t, deployment.GetAgentFolder(targetDir, agentVersion))
+	})
+}
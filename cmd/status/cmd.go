@@ -0,0 +1,125 @@
+package status
+
+import (
+	"fmt"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/serverless/platform"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/exit"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/version"
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+)
+
+const (
+	Use = "status"
+
+	SourceFolderFlag = "source"
+	TargetFolderFlag = "target"
+	WorkFolderFlag   = "work"
+	TechnologyFlag   = "technology"
+	OutputFlag       = "output"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Exit codes per deployment status, Deployed exits with exit.Success.
+const (
+	ExitCodeNotDeployed = 10
+	ExitCodeLinkMissing = 11
+	ExitCodeUnknown     = 12
+	ExitCodeCorrupt     = 13
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                Use,
+		RunE:               run,
+		FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+		Version:            version.Version,
+		Short:              "Print the OneAgent deployment status, the exit code reflects the status",
+		// the status is printed in the requested format, the error is only used for the exit code
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	addFlags(cmd)
+
+	return cmd
+}
+
+var (
+	sourceFolder   string
+	targetFolder   string
+	workBaseFolder string
+	technology     string
+	output         string
+)
+
+func addFlags(cmd *cobra.Command) {
+	// the same defaults as for the serverless command, so the status of its deployment can be checked without any flags
+	defaultPaths := platform.Detect(platform.OSEnv(), platform.Providers...).DefaultPaths()
+
+	cmd.Flags().StringVar(&targetFolder, TargetFolderFlag, defaultPaths.Target, "Base path where the CodeModule is deployed to. Only required if the platform has no default.")
+
+	if defaultPaths.Target == "" {
+		err := cmd.MarkFlagRequired(TargetFolderFlag)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	cmd.Flags().StringVar(&sourceFolder, SourceFolderFlag, defaultPaths.Source, "(Optional) Base path where the CodeModule is copied from, used to determine the expected version.")
+	cmd.Flags().StringVar(&workBaseFolder, WorkFolderFlag, defaultPaths.Work, "(Optional) Base path to the working folder of the deployment, used to check the deployment lock and left over work folders.")
+	cmd.Flags().StringVar(&technology, TechnologyFlag, "", "(Optional) Comma-separated list of CodeModule technologies the deployment is expected to contain, the same as for the serverless command.")
+	cmd.Flags().StringVar(&output, OutputFlag, OutputText, "(Optional) Output format, either 'text' or 'json'.")
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	if output != OutputText && output != OutputJSON {
+		err := fmt.Errorf("invalid output format %q, must be %q or %q", output, OutputText, OutputJSON)
+		cmd.PrintErrln("Error:", err.Error())
+
+		return err
+	}
+
+	report, detailsErr := deployment.Inspect(logr.Discard(), sourceFolder, targetFolder, workBaseFolder, technology)
+
+	var err error
+	if output == OutputJSON {
+		err = printJSON(cmd.OutOrStdout(), report, detailsErr)
+	} else {
+		err = printText(cmd.OutOrStdout(), report, detailsErr)
+	}
+
+	if err != nil {
+		cmd.PrintErrln("Error:", err.Error())
+
+		return err
+	}
+
+	return exitError(report.Status)
+}
+
+// exitError returns an error with the exit code of the given status, nil if it is Deployed.
+func exitError(status deployment.Status) error {
+	var code int
+
+	switch status {
+	case deployment.Deployed:
+		return nil
+	case deployment.NotDeployed:
+		code = ExitCodeNotDeployed
+	case deployment.LinkMissing:
+		code = ExitCodeLinkMissing
+	case deployment.Corrupt:
+		code = ExitCodeCorrupt
+	default:
+		code = ExitCodeUnknown
+	}
+
+	return exit.WithCode(code, fmt.Errorf("OneAgent deployment status: %s", status))
+}
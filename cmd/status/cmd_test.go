@@ -0,0 +1,129 @@
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/exit"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const agentVersion = "1.327.30.20251107-111521"
+
+func TestStatusCmd(t *testing.T) {
+	t.Run("missing 'target' parameter results in an error", func(t *testing.T) {
+		cmd := New()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+
+		err := cmd.Execute()
+		require.ErrorContains(t, err, "required flag(s) \"target\" not set")
+		assert.Equal(t, exit.Failure, exit.Code(err))
+	})
+
+	t.Run("invalid output format results in an error", func(t *testing.T) {
+		_, err := execute(t, "--source", t.TempDir(), "--target", t.TempDir(), "--output", "yaml")
+		require.ErrorContains(t, err, "invalid output format")
+		assert.Equal(t, exit.Failure, exit.Code(err))
+	})
+
+	t.Run("deployed ==> exit code 0, text output", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		out, err := execute(t, "--source", sourceDir, "--target", targetDir, "--work", t.TempDir())
+		require.NoError(t, err)
+
+		assert.Contains(t, out, "Status:             Deployed\n")
+		assert.Contains(t, out, "Expected version:   "+agentVersion+"\n")
+		assert.Contains(t, out, "Active link target: "+agentVersion+"\n")
+		assert.Contains(t, out, "Lock:               free\n")
+		assert.Regexp(t, "\n  "+agentVersion+` \(\d+ B, all technologies, `+runtime.GOARCH+`\)\n`, out)
+	})
+
+	t.Run("not deployed ==> exit code, json output", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		out, err := execute(t, "--source", sourceDir, "--target", t.TempDir(), "--work", t.TempDir(), "--output", "json")
+		require.Error(t, err)
+		assert.Equal(t, ExitCodeNotDeployed, exit.Code(err))
+
+		var response Response
+		require.NoError(t, json.Unmarshal([]byte(out), &response))
+
+		assert.Equal(t, "Not deployed", response.Status)
+		assert.Equal(t, agentVersion, response.ExpectedVersion)
+		assert.Empty(t, response.DeployedVersions)
+		assert.False(t, response.Lock.Held)
+	})
+
+	t.Run("link missing ==> exit code", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, "")
+
+		_, err := execute(t, "--source", sourceDir, "--target", targetDir, "--work", t.TempDir())
+		assert.Equal(t, ExitCodeLinkMissing, exit.Code(err))
+	})
+
+	t.Run("corrupt ==> exit code, incomplete version", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+		require.NoError(t, os.Remove(filepath.Join(deployment.GetAgentFolder(targetDir, agentVersion), marker.FileName)))
+
+		out, err := execute(t, "--source", sourceDir, "--target", targetDir, "--work", t.TempDir())
+		assert.Equal(t, ExitCodeCorrupt, exit.Code(err))
+
+		assert.Contains(t, out, "Status:             Corrupt\n")
+		assert.Contains(t, out, "  "+agentVersion+" (0 B, all technologies, <none>) incomplete\n")
+	})
+
+	t.Run("status error ==> exit code, error in output", func(t *testing.T) {
+		out, err := execute(t, "--source", filepath.Join(t.TempDir(), "missing"), "--target", t.TempDir(), "--work", t.TempDir(), "--output", "json")
+		assert.Equal(t, ExitCodeUnknown, exit.Code(err))
+
+		var response Response
+		require.NoError(t, json.Unmarshal([]byte(out), &response))
+
+		assert.Equal(t, "Unknown", response.Status)
+		assert.Contains(t, response.Error, "failed to determine OneAgent version to deploy")
+	})
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "1.5 KiB", formatSize(1536))
+	assert.Equal(t, "200.0 MiB", formatSize(200*1024*1024))
+}
+
+func execute(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+
+	cmd := New()
+	cmd.SetArgs(args)
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+
+	return out.String(), err
+}
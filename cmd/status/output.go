@@ -0,0 +1,159 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+)
+
+// Response is the JSON representation of the deployment.Report.
+type Response struct {
+	Status           string            `json:"status"`
+	ExpectedVersion  string            `json:"expectedVersion,omitempty"`
+	ActiveLinkTarget string            `json:"activeLinkTarget,omitempty"`
+	Error            string            `json:"error,omitempty"`
+	DeployedVersions []DeployedVersion `json:"deployedVersions"`
+	StaleWorkFolders []string          `json:"staleWorkFolders"`
+	Lock             Lock              `json:"lock"`
+}
+
+type DeployedVersion struct {
+	Version      string `json:"version"`
+	Technologies string `json:"technologies,omitempty"`
+	Arch         string `json:"arch,omitempty"`
+	Size         int64  `json:"size"`
+	Complete     bool   `json:"complete"`
+}
+
+type Lock struct {
+	Age   string `json:"age,omitempty"`
+	Owner string `json:"owner,omitempty"`
+	Token uint64 `json:"token,omitempty"`
+	Held  bool   `json:"held"`
+	Stale bool   `json:"stale"`
+}
+
+func newResponse(report deployment.Report, detailsErr error) Response {
+	response := Response{
+		Status:           report.Status.String(),
+		ExpectedVersion:  report.AgentVersion,
+		ActiveLinkTarget: report.ActiveLinkTarget,
+		DeployedVersions: make([]DeployedVersion, 0, len(report.DeployedVersions)),
+		StaleWorkFolders: make([]string, 0, len(report.StaleWorkFolders)),
+		Lock: Lock{
+			Held:  report.Lock.Held,
+			Stale: report.Lock.Stale,
+			Token: report.Lock.Token,
+			Owner: report.Lock.Owner,
+		},
+	}
+
+	if report.Lock.Held {
+		response.Lock.Age = report.Lock.Age.Round(time.Second).String()
+	}
+
+	for _, deployed := range report.DeployedVersions {
+		response.DeployedVersions = append(response.DeployedVersions, DeployedVersion(deployed))
+	}
+
+	response.StaleWorkFolders = append(response.StaleWorkFolders, report.StaleWorkFolders...)
+
+	if err := errors.Join(report.Error, detailsErr); err != nil {
+		response.Error = err.Error()
+	}
+
+	return response
+}
+
+func printJSON(w io.Writer, report deployment.Report, detailsErr error) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(newResponse(report, detailsErr))
+}
+
+func printText(w io.Writer, report deployment.Report, detailsErr error) error {
+	response := newResponse(report, detailsErr)
+
+	lines := []string{
+		fmt.Sprintf("Status:             %s", response.Status),
+		fmt.Sprintf("Expected version:   %s", valueOrNone(response.ExpectedVersion)),
+		fmt.Sprintf("Active link target: %s", valueOrNone(response.ActiveLinkTarget)),
+		fmt.Sprintf("Lock:               %s", formatLock(response.Lock)),
+		"Deployed versions:",
+	}
+
+	for _, deployed := range response.DeployedVersions {
+		line := fmt.Sprintf("  %s (%s, %s, %s)", deployed.Version, formatSize(deployed.Size), valueOrAll(deployed.Technologies), valueOrNone(deployed.Arch))
+		if !deployed.Complete {
+			line += " incomplete"
+		}
+
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "Stale work folders:")
+
+	for _, folder := range response.StaleWorkFolders {
+		lines = append(lines, "  "+folder)
+	}
+
+	if response.Error != "" {
+		lines = append(lines, "Error: "+response.Error)
+	}
+
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatLock(lock Lock) string {
+	switch {
+	case !lock.Held:
+		return "free"
+	case lock.Stale:
+		return fmt.Sprintf("stale (age %s, token %d, owner %s)", lock.Age, lock.Token, valueOrNone(lock.Owner))
+	default:
+		return fmt.Sprintf("held (age %s, token %d, owner %s)", lock.Age, lock.Token, valueOrNone(lock.Owner))
+	}
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func valueOrAll(technologies string) string {
+	if technologies == "" {
+		return "all technologies"
+	}
+
+	return technologies
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+
+	return value
+}
@@ -0,0 +1,234 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/configure"
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/configure/attributes/container"
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit/configure/attributes/pod"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/enrichment/endpoint"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/ca"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/curl"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pgc"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/proxy"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/exit"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+const (
+	Use = "validate"
+
+	OutputFlag = "output"
+)
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                Use,
+		RunE:               run,
+		FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+		Version:            version.Version,
+		Short:              "Validate the configuration inputs without writing anything, the exit code reflects the result",
+		// the report is printed in the requested format, the error is only used for the exit code
+		SilenceErrors: true,
+		SilenceUsage:  true,
+	}
+
+	addFlags(cmd)
+
+	return cmd
+}
+
+var (
+	inputDir            string
+	podAttributes       []string
+	containerAttributes []string
+	output              string
+)
+
+func addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&inputDir, configure.InputFolderFlag, "", "Base path where to look for the configuration files.")
+
+	err := cmd.MarkFlagRequired(configure.InputFolderFlag)
+	if err != nil {
+		panic(err)
+	}
+
+	cmd.Flags().StringArrayVar(&podAttributes, pod.Flag, []string{}, "(Optional) Pod-specific attributes in key=value format.")
+	cmd.Flags().StringArrayVar(&containerAttributes, container.Flag, []string{}, "(Optional) Container-specific attributes in JSON format.")
+	cmd.Flags().StringVar(&output, OutputFlag, OutputText, "(Optional) Output format, either 'text' or 'json'.")
+}
+
+// inputFile is a file in the input directory, and the function to validate its content.
+type inputFile struct {
+	validate func(raw []byte) error
+	name     string
+}
+
+// inputFiles are validated in the order of the configuration steps.
+var inputFiles = []inputFile{
+	{name: pmc.InputFileName, validate: pmc.ValidateInput},
+	{name: proxy.InputFileName, validate: proxy.ValidateInput},
+	{name: curl.InputFileName, validate: curl.ValidateInput},
+	{name: curl.OptionsJSONInputFileName, validate: curl.ValidateOptionsInput},
+	{name: curl.OptionsYAMLInputFileName, validate: curl.ValidateOptionsInput},
+	{name: ca.TrustedCertsInputFile, validate: ca.ValidateInput},
+	{name: ca.AgCertsInputFile, validate: ca.ValidateInput},
+	{name: pgc.InputFileName, validate: pgc.ValidateInput},
+	{name: endpoint.InputFileName, validate: endpoint.ValidateInput},
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	if output != OutputText && output != OutputJSON {
+		err := fmt.Errorf("invalid output format %q, must be %q or %q", output, OutputText, OutputJSON)
+		cmd.PrintErrln("Error:", err.Error())
+
+		return err
+	}
+
+	response := validate()
+
+	var err error
+	if output == OutputJSON {
+		err = printJSON(cmd.OutOrStdout(), response)
+	} else {
+		err = printText(cmd.OutOrStdout(), response)
+	}
+
+	if err != nil {
+		cmd.PrintErrln("Error:", err.Error())
+
+		return err
+	}
+
+	if !response.Valid {
+		return exit.WithCode(exit.Failure, errors.New("the configuration inputs are invalid"))
+	}
+
+	return nil
+}
+
+func validate() Response {
+	response := Response{Valid: true}
+
+	add := func(input, status string, err error) {
+		result := Result{Input: input, Status: status}
+		if err != nil {
+			result.Error = err.Error()
+			response.Valid = false
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	if info, err := os.Stat(inputDir); err != nil {
+		add(inputDir, StatusInvalid, err)
+	} else if !info.IsDir() {
+		add(inputDir, StatusInvalid, errors.New("must be a directory"))
+	}
+
+	for _, file := range inputFiles {
+		raw, err := os.ReadFile(filepath.Join(inputDir, file.name))
+		if errors.Is(err, fs.ErrNotExist) {
+			add(file.name, StatusNotPresent, nil)
+
+			continue
+		}
+
+		if err == nil {
+			err = file.validate(raw)
+		}
+
+		add(file.name, statusOf(err), err)
+	}
+
+	certsDirFiles, err := ca.GetCertsDirFiles(inputDir)
+	if err != nil {
+		add(ca.CertsInputDir, StatusInvalid, err)
+	}
+
+	for _, name := range certsDirFiles {
+		raw, err := os.ReadFile(filepath.Join(inputDir, name))
+		if err == nil {
+			err = ca.ValidateInput(raw)
+		}
+
+		add(name, statusOf(err), err)
+	}
+
+	err = validatePodAttributes(podAttributes)
+	add(pod.Flag, statusOf(err), err)
+
+	err = validateContainerAttributes(containerAttributes)
+	add(container.Flag, statusOf(err), err)
+
+	return response
+}
+
+func statusOf(err error) string {
+	if err != nil {
+		return StatusInvalid
+	}
+
+	return StatusValid
+}
+
+// validatePodAttributes reports the attributes, which are silently ignored by pod.ParseAttributes.
+func validatePodAttributes(rawAttributes []string) error {
+	var errs []error
+
+	for i, attr := range rawAttributes {
+		key, value, found := strings.Cut(attr, "=")
+		if !found || key == "" || strings.Contains(value, "=") {
+			errs = append(errs, fmt.Errorf("[%d]: must be in key=value format, got %q", i, attr))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	_, err := pod.ParseAttributes(rawAttributes)
+
+	return err
+}
+
+// validateContainerAttributes checks that every attribute can be parsed, and has a unique container name, as it is used as folder name.
+func validateContainerAttributes(rawAttributes []string) error {
+	var errs []error
+
+	names := map[string]bool{}
+
+	for i, attr := range rawAttributes {
+		parsed, err := container.ParseAttributes([]string{attr})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("[%d]: %w", i, err))
+
+			continue
+		}
+
+		name := parsed[0].ContainerName
+
+		switch {
+		case name == "" || name != filepath.Base(name) || name == "." || name == "..":
+			errs = append(errs, fmt.Errorf("[%d]: k8s.container.name must be a valid folder name, got %q", i, name))
+		case names[name]:
+			errs = append(errs, fmt.Errorf("[%d]: duplicate k8s.container.name %q", i, name))
+		}
+
+		names[name] = true
+	}
+
+	return errors.Join(errs...)
+}
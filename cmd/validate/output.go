@@ -0,0 +1,69 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// The status of a single input.
+const (
+	StatusValid      = "valid"
+	StatusInvalid    = "invalid"
+	StatusNotPresent = "not present"
+)
+
+// Response is the report of the validation.
+type Response struct {
+	Results []Result `json:"results"`
+	Valid   bool     `json:"valid"`
+}
+
+// Result is the validation result of a single input, i.e. a file in the input directory or the attributes.
+type Result struct {
+	Input  string `json:"input"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+func printJSON(w io.Writer, response Response) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(response)
+}
+
+func printText(w io.Writer, response Response) error {
+	const padding = 2
+
+	tw := tabwriter.NewWriter(w, 0, 0, padding, ' ', 0)
+
+	_, _ = fmt.Fprintln(tw, "INPUT\tSTATUS\tERROR")
+
+	for _, result := range response.Results {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", result.Input, result.Status, valueOr(strings.ReplaceAll(result.Error, "\n", "; "), "-"))
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	result := StatusValid
+	if !response.Valid {
+		result = StatusInvalid
+	}
+
+	_, err := fmt.Fprintf(w, "\nResult: %s\n", result)
+
+	return err
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -25,6 +25,10 @@ go/coverage: go/test
 go/gen_mocks: prerequisites/mockery
 	mockery
 
+## Regenerates the published config file schemas from the flags
+go/gen_schemas:
+	UPDATE_GOLDEN_FILES=true go test ./cmd/k8sinit ./cmd/serverless -run TestConfigFile
+
 ## Runs deadcode https://go.dev/blog/deadcode
 go/deadcode: prerequisites/go-deadcode
 	# we add `tee` in the end to make it fail if it finds dead code, by default deadcode always return exit code 0
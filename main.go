@@ -3,8 +3,12 @@ package main
 import (
 	"os"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/history"
 	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/k8sinit"
 	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/serverless"
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/status"
+	"github.com/Dynatrace/dynatrace-bootstrapper/cmd/validate"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/exit"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +18,7 @@ func main() {
 		Short:              "Simple binary for setting up the OneAgent CodeModule in different envs.",
 		Long:               "The purpose of the bootstrapper is to copy and configure the OneAgent CodeModule. If no subcommand is specified, the 'k8s-init' subcommand will run for backward compatibility.",
 		FParseErrWhitelist: cobra.FParseErrWhitelist{UnknownFlags: true},
+		PreRunE:            k8sinit.PreRunE,
 		RunE:               k8sinit.RunE,
 	}
 
@@ -23,10 +28,13 @@ func main() {
 	rootCmd.AddCommand(
 		k8sinit.New(),
 		serverless.New(),
+		status.New(),
+		history.New(),
+		validate.New(),
 	)
 
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		os.Exit(exit.Code(err))
 	}
 }
@@ -1,8 +1,12 @@
 package endpoint
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 
 	fsutils "github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs"
 	"github.com/go-logr/logr"
@@ -11,6 +15,9 @@ import (
 const (
 	ConfigBasePath = "enrichment/endpoint"
 	InputFileName  = "endpoint.properties"
+
+	URLProperty   = "DT_METRICS_INGEST_URL"
+	TokenProperty = "DT_METRICS_INGEST_API_TOKEN"
 )
 
 func Configure(log logr.Logger, inputDir, configDir string) error {
@@ -45,3 +52,34 @@ func getFromFs(inputDir string) (string, error) {
 
 	return string(content), err
 }
+
+// ValidateInput checks that the content of the input file has a valid ingest URL and a token.
+func ValidateInput(raw []byte) error {
+	properties := map[string]string{}
+
+	for i, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return fmt.Errorf("line %d: must be in key=value format", i+1)
+		}
+
+		properties[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	var errs []error
+
+	if ingestURL, err := url.Parse(properties[URLProperty]); err != nil || (ingestURL.Scheme != "https" && ingestURL.Scheme != "http") || ingestURL.Host == "" {
+		errs = append(errs, fmt.Errorf("%s: must be an absolute http(s) URL, got %q", URLProperty, properties[URLProperty]))
+	}
+
+	if properties[TokenProperty] == "" {
+		errs = append(errs, fmt.Errorf("%s: must not be empty", TokenProperty))
+	}
+
+	return errors.Join(errs...)
+}
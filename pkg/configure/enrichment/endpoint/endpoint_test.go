@@ -50,3 +50,19 @@ func setupFs(t *testing.T, inputDir, value string) {
 
 	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, InputFileName), value))
 }
+
+func TestValidateInput(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		require.NoError(t, ValidateInput([]byte("# comment\nDT_METRICS_INGEST_URL=https://tenant.live.dynatrace.com/api/v2/metrics/ingest\n\nDT_METRICS_INGEST_API_TOKEN=dt0c01.token\n")))
+	})
+
+	t.Run("invalid URL and missing token", func(t *testing.T) {
+		err := ValidateInput([]byte("DT_METRICS_INGEST_URL=tenant.live.dynatrace.com\n"))
+		require.ErrorContains(t, err, "DT_METRICS_INGEST_URL: must be an absolute http(s) URL")
+		require.ErrorContains(t, err, "DT_METRICS_INGEST_API_TOKEN: must not be empty")
+	})
+
+	t.Run("malformed line", func(t *testing.T) {
+		require.ErrorContains(t, ValidateInput([]byte("DT_METRICS_INGEST_URL=https://tenant\nnot-a-property\n")), "line 2: must be in key=value format")
+	})
+}
@@ -41,6 +41,10 @@ func (c fileContent) toJSON() ([]byte, error) {
 		return nil, err
 	}
 
+	return mapToJSON(rawMap)
+}
+
+func mapToJSON(rawMap map[string]string) ([]byte, error) {
 	raw, err := json.Marshal(rawMap)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -50,13 +54,17 @@ func (c fileContent) toJSON() ([]byte, error) {
 }
 
 func (c fileContent) toProperties() (string, error) {
-	var confContent strings.Builder
-
 	contentMap, err := c.toMap()
 	if err != nil {
 		return "", err
 	}
 
+	return mapToProperties(contentMap), nil
+}
+
+func mapToProperties(contentMap map[string]string) string {
+	var confContent strings.Builder
+
 	for key, value := range contentMap {
 		_, _ = confContent.WriteString(key)
 		_, _ = confContent.WriteString("=")
@@ -64,7 +72,7 @@ func (c fileContent) toProperties() (string, error) {
 		_, _ = confContent.WriteString("\n")
 	}
 
-	return confContent.String(), nil
+	return confContent.String()
 }
 
 func fromAttributes(containerAttr container.Attributes, podAttr pod.Attributes, withDeprecatedAttributes bool) fileContent {
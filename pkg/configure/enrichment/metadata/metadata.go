@@ -24,17 +24,34 @@ func Configure(log logr.Logger, configDirectory string, podAttr pod.Attributes,
 		return err
 	}
 
-	jsonFilePath := filepath.Join(configDirectory, JSONFilePath)
-
-	err = fsutils.CreateFile(jsonFilePath, string(confJSON))
+	confProperties, err := confContent.toProperties()
 	if err != nil {
-		log.Error(err, "failed to create metadata-enrichment properties file", "struct", jsonFilePath)
+		return err
+	}
+
+	return createFiles(log, configDirectory, confJSON, confProperties)
+}
+
+// ConfigureFromMap creates the metadata-enrichment files with the given attributes, in the same format as Configure.
+// Used outside of Kubernetes, where the attributes are not based on a Pod and its containers.
+func ConfigureFromMap(log logr.Logger, configDirectory string, attributes map[string]string) error {
+	log.V(1).Info("format content into a raw form", "attributes", attributes)
 
+	confJSON, err := mapToJSON(attributes)
+	if err != nil {
 		return err
 	}
 
-	confProperties, err := confContent.toProperties()
+	return createFiles(log, configDirectory, confJSON, mapToProperties(attributes))
+}
+
+func createFiles(log logr.Logger, configDirectory string, confJSON []byte, confProperties string) error {
+	jsonFilePath := filepath.Join(configDirectory, JSONFilePath)
+
+	err := fsutils.CreateFile(jsonFilePath, string(confJSON))
 	if err != nil {
+		log.Error(err, "failed to create metadata-enrichment properties file", "struct", jsonFilePath)
+
 		return err
 	}
 
@@ -72,3 +72,28 @@ func TestConfigure(t *testing.T) {
 		}
 	})
 }
+
+func TestConfigureFromMap(t *testing.T) {
+	attributes := map[string]string{
+		"cloud.provider": "azure",
+		"service.name":   "my-site",
+	}
+
+	t.Run("success", func(t *testing.T) {
+		configDir := filepath.Join(t.TempDir(), "path", "config")
+
+		err := ConfigureFromMap(testLog, configDir, attributes)
+		require.NoError(t, err)
+
+		jsonContent, err := os.ReadFile(filepath.Join(configDir, JSONFilePath))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"cloud.provider":"azure","service.name":"my-site"}`, string(jsonContent))
+
+		propsContent, err := os.ReadFile(filepath.Join(configDir, PropertiesFilePath))
+		require.NoError(t, err)
+
+		for key, value := range attributes {
+			assert.Contains(t, string(propsContent), key+"="+value+"\n")
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileName is the name of the file in the config directory, which holds the fingerprint of the inputs of the last successful configuration.
+const FileName = ".dt-configuration-fingerprint"
+
+// format is part of the hashed input, so changing the way the fingerprint is calculated invalidates all stored fingerprints.
+const format = "v1"
+
+const filePerm644 fs.FileMode = 0o644
+
+// Compute returns the SHA-256 fingerprint of the content of every file in the input directory and the given values (e.g., the attributes, the flags and the OneAgent version).
+// Symlinks are followed, and entries starting with ".." are skipped, so re-mounting the same Kubernetes Secret or ConfigMap as input directory does not change the fingerprint.
+// A missing input directory is hashed as an empty one.
+func Compute(inputDir string, values ...string) (string, error) {
+	hasher := sha256.New()
+
+	_, _ = fmt.Fprintf(hasher, "%s\n", format)
+
+	for _, value := range values {
+		// the NUL separator can't be part of a flag value, so different values can't produce the same input
+		_, _ = fmt.Fprintf(hasher, "%s\x00", value)
+	}
+
+	_, _ = fmt.Fprint(hasher, "\n")
+
+	if _, err := os.Stat(inputDir); err == nil {
+		if err := hashDir(hasher, inputDir, "."); err != nil {
+			return "", fmt.Errorf("failed to hash the input directory %s: %w", inputDir, err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to hash the input directory %s: %w", inputDir, err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// Read returns the fingerprint stored in the config directory, empty if there is none.
+func Read(configDir string) (string, error) {
+	raw, err := os.ReadFile(filepath.Join(configDir, FileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("failed to read the fingerprint: %w", err)
+	}
+
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// Write stores the fingerprint in the config directory.
+// It must be the last change to the config directory, so an interrupted configuration is not mistaken for a complete one.
+func Write(configDir, fingerprint string) error {
+	if err := os.MkdirAll(configDir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create the config directory %s: %w", configDir, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(configDir, FileName), []byte(fingerprint+"\n"), filePerm644); err != nil {
+		return fmt.Errorf("failed to write the fingerprint: %w", err)
+	}
+
+	return nil
+}
+
+func hashDir(hasher hash.Hash, dir, relDir string) error {
+	entries, err := os.ReadDir(filepath.Join(dir, relDir))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "..") {
+			continue
+		}
+
+		relPath := filepath.Join(relDir, entry.Name())
+
+		// os.Stat follows the symlinks, unlike the fs.DirEntry
+		info, err := os.Stat(filepath.Join(dir, relPath))
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if err := hashDir(hasher, dir, relPath); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		raw, err := os.ReadFile(filepath.Join(dir, relPath))
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(hasher, "%s\x00%d\x00", relPath, len(raw))
+		_, _ = hasher.Write(raw)
+	}
+
+	return nil
+}
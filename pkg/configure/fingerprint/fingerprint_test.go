@@ -0,0 +1,115 @@
+package fingerprint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupInputDir(t *testing.T) string {
+	t.Helper()
+
+	inputDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "ruxitagentproc.json"), []byte(`{}`), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(inputDir, "nested"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(inputDir, "nested", "file"), []byte("content"), 0o600))
+
+	return inputDir
+}
+
+func TestCompute(t *testing.T) {
+	t.Run("same inputs produce the same fingerprint", func(t *testing.T) {
+		inputDir := setupInputDir(t)
+
+		first, err := Compute(inputDir, "a=1", "b=2")
+		require.NoError(t, err)
+
+		second, err := Compute(inputDir, "a=1", "b=2")
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		assert.NotEmpty(t, first)
+	})
+
+	t.Run("changed file content changes the fingerprint", func(t *testing.T) {
+		inputDir := setupInputDir(t)
+
+		before, err := Compute(inputDir)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(inputDir, "nested", "file"), []byte("changed"), 0o600))
+
+		after, err := Compute(inputDir)
+		require.NoError(t, err)
+
+		assert.NotEqual(t, before, after)
+	})
+
+	t.Run("changed values change the fingerprint", func(t *testing.T) {
+		inputDir := setupInputDir(t)
+
+		before, err := Compute(inputDir, "a=1", "b=2")
+		require.NoError(t, err)
+
+		after, err := Compute(inputDir, "a=1b=2")
+		require.NoError(t, err)
+
+		assert.NotEqual(t, before, after)
+	})
+
+	t.Run("remounted Kubernetes volume keeps the fingerprint", func(t *testing.T) {
+		inputDir := t.TempDir()
+
+		mount := func(timestamp string) {
+			require.NoError(t, os.MkdirAll(filepath.Join(inputDir, timestamp), os.ModePerm))
+			require.NoError(t, os.WriteFile(filepath.Join(inputDir, timestamp, "ruxitagentproc.json"), []byte(`{}`), 0o600))
+			_ = os.Remove(filepath.Join(inputDir, "..data"))
+			require.NoError(t, os.Symlink(timestamp, filepath.Join(inputDir, "..data")))
+		}
+
+		mount("..2026_01_01_00_00_00.1")
+		require.NoError(t, os.Symlink(filepath.Join("..data", "ruxitagentproc.json"), filepath.Join(inputDir, "ruxitagentproc.json")))
+
+		before, err := Compute(inputDir)
+		require.NoError(t, err)
+
+		mount("..2026_01_02_00_00_00.2")
+
+		after, err := Compute(inputDir)
+		require.NoError(t, err)
+
+		assert.Equal(t, before, after)
+
+		empty, err := Compute(t.TempDir())
+		require.NoError(t, err)
+
+		assert.NotEqual(t, empty, after)
+	})
+
+	t.Run("missing input directory", func(t *testing.T) {
+		missing, err := Compute(filepath.Join(t.TempDir(), "missing"))
+		require.NoError(t, err)
+
+		empty, err := Compute(t.TempDir())
+		require.NoError(t, err)
+
+		assert.Equal(t, empty, missing)
+	})
+}
+
+func TestReadWrite(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), "config")
+
+	stored, err := Read(configDir)
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+
+	require.NoError(t, Write(configDir, "fingerprint"))
+
+	stored, err = Read(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, "fingerprint", stored)
+}
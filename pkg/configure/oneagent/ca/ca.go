@@ -1,8 +1,17 @@
 package ca
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"time"
 
 	fsutils "github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs"
 	"github.com/go-logr/logr"
@@ -15,34 +24,100 @@ const (
 
 	TrustedCertsInputFile = "trusted.pem"
 	AgCertsInputFile      = "activegate.pem"
+	CertsInputDir         = "certs"
+
+	pemCertificateType = "CERTIFICATE"
+
+	// expiryWarningPeriod is the time before the expiry of a certificate, from which on it is reported as expiring soon.
+	expiryWarningPeriod = 30 * 24 * time.Hour
 )
 
-func Configure(log logr.Logger, inputDir, configDir string) error {
-	trustedCerts, err := GetFromFs(inputDir, TrustedCertsInputFile)
-	if err != nil && !os.IsNotExist(err) {
+// certsDirPatterns are the patterns of the files in the certs directory, which are read as trusted certificates.
+var certsDirPatterns = []string{"*.pem", "*.crt"}
+
+// Bundles are the certificate bundles of the containers, which are the same for all containers of a pod, so they are only parsed once per pod.
+type Bundles struct {
+	// certs are written to the custom.pem
+	certs []*x509.Certificate
+	// proxyCerts are written to the custom_proxy.pem
+	proxyCerts []*x509.Certificate
+}
+
+// Configure writes the certificates of the ActiveGate and all trusted certificates as a normalized PEM bundle,
+// and the trusted certificates also as the proxy bundle.
+func Configure(log logr.Logger, inputDir, configDir, systemCAFile string) error {
+	bundles, err := Parse(log, inputDir, systemCAFile)
+	if err != nil {
 		return err
 	}
 
-	agCerts, err := GetFromFs(inputDir, AgCertsInputFile)
-	if err != nil && !os.IsNotExist(err) {
-		return err
+	return bundles.Write(log, configDir)
+}
+
+// Parse reads the certificates of the input files, and the system CA file if it is set, the latter must exist.
+// The trusted certificates are the ones of the trusted.pem, the *.pem and *.crt files in the certs directory and the system CA file.
+// Duplicated certificates are only kept once, expired and not yet valid certificates are dropped, and a malformed file is an error.
+func Parse(log logr.Logger, inputDir, systemCAFile string) (Bundles, error) {
+	trustedCerts, err := readInputCertificates(log, inputDir, TrustedCertsInputFile)
+	if err != nil {
+		return Bundles{}, err
+	}
+
+	certsDirFiles, err := GetCertsDirFiles(inputDir)
+	if err != nil {
+		return Bundles{}, err
+	}
+
+	for _, certFileName := range certsDirFiles {
+		certs, err := readInputCertificates(log, inputDir, certFileName)
+		if err != nil {
+			return Bundles{}, err
+		}
+
+		trustedCerts = append(trustedCerts, certs...)
+	}
+
+	if systemCAFile != "" {
+		log.Info("merging the system CA file", "path", systemCAFile)
+
+		certs, err := readCertificates(log, systemCAFile, systemCAFile)
+		if err != nil {
+			return Bundles{}, fmt.Errorf("failed to read the system CA file: %w", err)
+		}
+
+		trustedCerts = append(trustedCerts, certs...)
 	}
 
-	if agCerts != "" || trustedCerts != "" {
+	agCerts, err := readInputCertificates(log, inputDir, AgCertsInputFile)
+	if err != nil {
+		return Bundles{}, err
+	}
+
+	trustedCerts = deduplicate(trustedCerts)
+
+	return Bundles{
+		certs:      deduplicate(slices.Concat(agCerts, trustedCerts)),
+		proxyCerts: trustedCerts,
+	}, nil
+}
+
+// Write creates the custom.pem and custom_proxy.pem in the config directory of a container, if they have any certificates.
+func (b Bundles) Write(log logr.Logger, configDir string) error {
+	if len(b.certs) > 0 {
 		certFilePath := filepath.Join(configDir, ConfigBasePath, CertsFileName)
-		log.Info("creating cert file", "path", certFilePath)
+		log.Info("creating cert file", "path", certFilePath, "certificates", len(b.certs))
 
-		err := fsutils.CreateFile(certFilePath, agCerts+"\n"+trustedCerts)
+		err := fsutils.CreateFile(certFilePath, encodeCertificates(b.certs))
 		if err != nil {
 			return err
 		}
 	}
 
-	if trustedCerts != "" {
+	if len(b.proxyCerts) > 0 {
 		proxyCertFilePath := filepath.Join(configDir, ConfigBasePath, ProxyCertsFileName)
-		log.Info("creating cert file", "path", proxyCertFilePath)
+		log.Info("creating cert file", "path", proxyCertFilePath, "certificates", len(b.proxyCerts))
 
-		err := fsutils.CreateFile(proxyCertFilePath, trustedCerts)
+		err := fsutils.CreateFile(proxyCertFilePath, encodeCertificates(b.proxyCerts))
 		if err != nil {
 			return err
 		}
@@ -51,13 +126,161 @@ func Configure(log logr.Logger, inputDir, configDir string) error {
 	return nil
 }
 
-func GetFromFs(inputDir, certFileName string) (string, error) {
-	inputFile := filepath.Join(inputDir, certFileName)
+// GetCertsDirFiles returns the paths of the *.pem and *.crt files in the certs directory, relative to the input directory.
+// A missing certs directory has no files.
+func GetCertsDirFiles(inputDir string) ([]string, error) {
+	var certFileNames []string
+
+	for _, pattern := range certsDirPatterns {
+		matches, err := filepath.Glob(filepath.Join(inputDir, CertsInputDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			// e.g., the timestamped folders of a mounted Secret
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+
+			certFileNames = append(certFileNames, filepath.Join(CertsInputDir, filepath.Base(match)))
+		}
+	}
+
+	slices.Sort(certFileNames)
+
+	return certFileNames, nil
+}
+
+// readInputCertificates returns the certificates of the file in the input directory, a missing file has no certificates.
+func readInputCertificates(log logr.Logger, inputDir, certFileName string) ([]*x509.Certificate, error) {
+	certs, err := readCertificates(log, filepath.Join(inputDir, certFileName), certFileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return certs, err
+}
+
+// readCertificates parses the file, and returns its unique certificates which are currently valid.
+func readCertificates(log logr.Logger, path, name string) ([]*x509.Certificate, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
 
-	content, err := os.ReadFile(inputFile)
+	certs, err := parseCertificates(content)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("invalid certificates in %s: %w", name, err)
+	}
+
+	return filterValid(log, name, deduplicate(certs), time.Now()), nil
+}
+
+// filterValid drops the certificates which are expired or not yet valid at the given time,
+// and warns about the certificates which expire within the expiryWarningPeriod.
+func filterValid(log logr.Logger, certFileName string, certs []*x509.Certificate, now time.Time) []*x509.Certificate {
+	valid := make([]*x509.Certificate, 0, len(certs))
+
+	for _, cert := range certs {
+		certLog := log.WithValues("file", certFileName, "subject", cert.Subject.String(), "fingerprint", fingerprint(cert))
+
+		switch {
+		case now.After(cert.NotAfter):
+			certLog.Info("dropping expired certificate", "not after", cert.NotAfter)
+
+			continue
+		case now.Before(cert.NotBefore):
+			certLog.Info("dropping not yet valid certificate", "not before", cert.NotBefore)
+
+			continue
+		case now.Add(expiryWarningPeriod).After(cert.NotAfter):
+			certLog.Info("certificate expires soon", "not after", cert.NotAfter)
+		}
+
+		valid = append(valid, cert)
+	}
+
+	return valid
+}
+
+// deduplicate removes the certificates with the same fingerprint, keeping the first occurrence.
+func deduplicate(certs []*x509.Certificate) []*x509.Certificate {
+	seen := make(map[string]bool, len(certs))
+	unique := make([]*x509.Certificate, 0, len(certs))
+
+	for _, cert := range certs {
+		fp := fingerprint(cert)
+		if seen[fp] {
+			continue
+		}
+
+		seen[fp] = true
+
+		unique = append(unique, cert)
+	}
+
+	return unique
+}
+
+// fingerprint is the hex encoded SHA-256 hash of the DER encoded certificate.
+func fingerprint(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.Raw)
+
+	return hex.EncodeToString(hash[:])
+}
+
+func encodeCertificates(certs []*x509.Certificate) string {
+	var bundle bytes.Buffer
+
+	for _, cert := range certs {
+		// writing to a bytes.Buffer does not fail
+		_ = pem.Encode(&bundle, &pem.Block{Type: pemCertificateType, Bytes: cert.Raw})
+	}
+
+	return bundle.String()
+}
+
+// ValidateInput checks that every PEM block of the input file is an X.509 certificate.
+func ValidateInput(raw []byte) error {
+	_, err := parseCertificates(raw)
+
+	return err
+}
+
+// parseCertificates decodes every PEM block of the content as an X.509 certificate, any other content than whitespace is an error.
+func parseCertificates(raw []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+
+	rest := raw
+
+	for i := 1; ; i++ {
+		var block *pem.Block
+
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != pemCertificateType {
+			return nil, fmt.Errorf("PEM block %d: unexpected type %q, must be %q", i, block.Type, pemCertificateType)
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("PEM block %d: %w", i, err)
+		}
+
+		certs = append(certs, cert)
+	}
+
+	if len(bytes.TrimSpace(rest)) > 0 {
+		if len(certs) == 0 {
+			return nil, errors.New("no PEM encoded certificate found")
+		}
+
+		return nil, fmt.Errorf("unexpected content after PEM block %d", len(certs))
 	}
 
-	return string(content), err
+	return certs, nil
 }
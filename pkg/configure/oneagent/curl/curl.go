@@ -1,46 +1,221 @@
 package curl
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	fsutils "github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs"
 	"github.com/go-logr/logr"
+	"gopkg.in/yaml.v3"
 )
 
 const (
-	optionsFormatString = `initialConnectRetryMs %s\n`
-	ConfigPath          = "oneagent/agent/customkeys/curl_options.conf"
-	InputFileName       = "initial-connect-retry"
+	ConfigPath    = "oneagent/agent/customkeys/curl_options.conf"
+	InputFileName = "initial-connect-retry"
+
+	OptionsJSONInputFileName = "curl-options.json"
+	OptionsYAMLInputFileName = "curl-options.yaml"
+
+	// InitialConnectRetryOption is the option set by the legacy initial-connect-retry input file.
+	InitialConnectRetryOption = "initialConnectRetryMs"
+
+	// options with this suffix are durations in milliseconds
+	millisecondsSuffix = "Ms"
 )
 
+// optionNamePattern prevents injecting further options or lines into the curl_options.conf via the option names.
+var optionNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// Options are the values of the curl_options.conf by option name.
+type Options map[string]string
+
+// String creates the content of the curl_options.conf, the options are printed in a sorted order, so it can be tested.
+func (o Options) String() string {
+	var content strings.Builder
+
+	for _, name := range slices.Sorted(maps.Keys(o)) {
+		_, _ = content.WriteString(name + " " + o[name] + "\n")
+	}
+
+	return content.String()
+}
+
 func Configure(log logr.Logger, inputDir, configDir string) error {
-	content, err := getFromFs(inputDir)
+	options, err := Read(log, inputDir)
+	if err != nil {
+		return err
+	}
+
+	if len(options) == 0 {
+		log.Info("input files not present, skipping curl options configuration", "path", filepath.Join(inputDir, InputFileName))
+
+		return nil
+	}
+
+	configFile := filepath.Join(configDir, ConfigPath)
+
+	log.Info("configuring curl_options.conf", "config-path", configFile, "options", slices.Sorted(maps.Keys(options)))
+
+	return fsutils.CreateFile(configFile, options.String())
+}
+
+// Read returns the options of the legacy initial-connect-retry input file, merged with the ones of the structured curl-options input file,
+// the latter take precedence. Only one of the curl-options.json and curl-options.yaml may be present.
+func Read(log logr.Logger, inputDir string) (Options, error) {
+	options := Options{}
+
+	raw, err := os.ReadFile(filepath.Join(inputDir, InputFileName))
+	if err == nil {
+		value, err := parseInitialConnectRetry(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s input file: %w", InputFileName, err)
+		}
+
+		options[InitialConnectRetryOption] = value
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	inputFileName, raw, err := readOptionsInputFile(inputDir)
+	if err != nil || raw == nil {
+		return options, err
+	}
+
+	structured, err := ParseOptions(raw)
 	if err != nil {
+		return nil, fmt.Errorf("invalid %s input file:\n%w", inputFileName, err)
+	}
+
+	for name, value := range structured {
+		if _, ok := options[name]; ok {
+			log.Info("option of the legacy input file is overridden", "option", name, "input file", inputFileName)
+		}
+
+		options[name] = value
+	}
+
+	return options, nil
+}
+
+// readOptionsInputFile returns the name and the content of the structured input file, the content is nil if there is none.
+func readOptionsInputFile(inputDir string) (string, []byte, error) {
+	var (
+		inputFileName string
+		content       []byte
+	)
+
+	for _, name := range []string{OptionsJSONInputFileName, OptionsYAMLInputFileName} {
+		raw, err := os.ReadFile(filepath.Join(inputDir, name))
 		if os.IsNotExist(err) {
-			log.Info("input file not present, skipping curl options configuration", "path", filepath.Join(inputDir, InputFileName))
+			continue
+		}
 
-			return nil
+		if err != nil {
+			return "", nil, err
 		}
 
-		return err
+		if content != nil {
+			return "", nil, fmt.Errorf("only one of the %s and %s input files may be present", OptionsJSONInputFileName, OptionsYAMLInputFileName)
+		}
+
+		inputFileName, content = name, raw
 	}
 
-	configFile := filepath.Join(configDir, ConfigPath)
+	return inputFileName, content, nil
+}
+
+// ParseOptions parses the content of a structured input file, which is an object of the options by name, in JSON or YAML format.
+// The values must be integers, booleans or single-line strings, options ending with "Ms" must be non-negative integers (milliseconds).
+// All invalid options are reported at once.
+func ParseOptions(raw []byte) (Options, error) {
+	// JSON is a subset of YAML, so both can be parsed the same way
+	var parsed map[string]any
+	if err := yaml.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("must be an object of the options by name: %w", err)
+	}
+
+	options := make(Options, len(parsed))
+
+	var errs []error
 
-	log.Info("configuring curl_options.conf", "config-path", configFile)
+	for _, name := range slices.Sorted(maps.Keys(parsed)) {
+		value, err := toOptionValue(name, parsed[name])
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		options[name] = value
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 
-	return fsutils.CreateFile(configFile, content)
+	return options, nil
 }
 
-func getFromFs(inputDir string) (string, error) {
-	inputFile := filepath.Join(inputDir, InputFileName)
+func toOptionValue(name string, value any) (string, error) {
+	if !optionNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%q: must be an alphanumeric option name", name)
+	}
+
+	if strings.HasSuffix(name, millisecondsSuffix) {
+		if i, ok := value.(int); ok && i >= 0 {
+			return strconv.Itoa(i), nil
+		}
+
+		return "", fmt.Errorf("%s: must be a non-negative integer (milliseconds)", name)
+	}
+
+	switch typed := value.(type) {
+	case int:
+		return strconv.Itoa(typed), nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case string:
+		if typed != "" && !strings.ContainsAny(typed, "\r\n") {
+			return typed, nil
+		}
+
+		return "", fmt.Errorf("%s: must be a non-empty single-line string", name)
+	default:
+		return "", fmt.Errorf("%s: must be an integer, a boolean or a string", name)
+	}
+}
 
-	content, err := os.ReadFile(inputFile)
+// parseInitialConnectRetry returns the trimmed value of the legacy input file, which is a non-negative integer (the retry delay in milliseconds).
+func parseInitialConnectRetry(raw []byte) (string, error) {
+	value, err := strconv.Atoi(strings.TrimSpace(string(raw)))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("must be an integer, got %q", string(raw))
+	}
+
+	if value < 0 {
+		return "", fmt.Errorf("must not be negative, got %d", value)
 	}
 
-	return fmt.Sprintf(optionsFormatString, string(content)), err
+	return strconv.Itoa(value), nil
+}
+
+// ValidateInput checks that the content of the legacy input file is a non-negative integer (the retry delay in milliseconds).
+func ValidateInput(raw []byte) error {
+	_, err := parseInitialConnectRetry(raw)
+
+	return err
+}
+
+// ValidateOptionsInput checks that the content of a structured input file has valid options.
+func ValidateOptionsInput(raw []byte) error {
+	_, err := ParseOptions(raw)
+
+	return err
 }
@@ -29,7 +29,66 @@ func TestConfigure(t *testing.T) {
 
 		content, err := os.ReadFile(filepath.Join(configDir, ConfigPath))
 		require.NoError(t, err)
-		assert.Contains(t, string(content), expectedValue)
+		assert.Equal(t, "initialConnectRetryMs "+expectedValue+"\n", string(content))
+	})
+
+	t.Run("legacy value is trimmed", func(t *testing.T) {
+		baseTempDir := filepath.Join(t.TempDir(), "path")
+		configDir := filepath.Join(baseTempDir, "config", "container")
+		inputDir := filepath.Join(baseTempDir, "input")
+
+		setupFs(t, inputDir, " "+expectedValue+"\n")
+
+		err := Configure(testLog, inputDir, configDir)
+		require.NoError(t, err)
+
+		content, err := os.ReadFile(filepath.Join(configDir, ConfigPath))
+		require.NoError(t, err)
+		assert.Equal(t, "initialConnectRetryMs "+expectedValue+"\n", string(content))
+	})
+
+	t.Run("invalid legacy value fails", func(t *testing.T) {
+		baseTempDir := filepath.Join(t.TempDir(), "path")
+		configDir := filepath.Join(baseTempDir, "config", "container")
+		inputDir := filepath.Join(baseTempDir, "input")
+
+		setupFs(t, inputDir, "123\ninjected 1")
+
+		err := Configure(testLog, inputDir, configDir)
+		require.ErrorContains(t, err, "invalid "+InputFileName+" input file: must be an integer")
+
+		_, err = os.ReadFile(filepath.Join(configDir, ConfigPath))
+		require.True(t, os.IsNotExist(err))
+	})
+
+	for _, inputFileName := range []string{OptionsJSONInputFileName, OptionsYAMLInputFileName} {
+		t.Run("structured options override the legacy value - "+inputFileName, func(t *testing.T) {
+			baseTempDir := filepath.Join(t.TempDir(), "path")
+			configDir := filepath.Join(baseTempDir, "config", "container")
+			inputDir := filepath.Join(baseTempDir, "input")
+
+			setupFs(t, inputDir, expectedValue)
+			require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, inputFileName), `{"initialConnectRetryMs": 5000, "connectTimeoutMs": 200, "verbose": true}`))
+
+			err := Configure(testLog, inputDir, configDir)
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(filepath.Join(configDir, ConfigPath))
+			require.NoError(t, err)
+			assert.Equal(t, "connectTimeoutMs 200\ninitialConnectRetryMs 5000\nverbose true\n", string(content))
+		})
+	}
+
+	t.Run("both structured input files fail", func(t *testing.T) {
+		baseTempDir := filepath.Join(t.TempDir(), "path")
+		configDir := filepath.Join(baseTempDir, "config", "container")
+		inputDir := filepath.Join(baseTempDir, "input")
+
+		require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, OptionsJSONInputFileName), `{"connectTimeoutMs": 200}`))
+		require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, OptionsYAMLInputFileName), "connectTimeoutMs: 200\n"))
+
+		err := Configure(testLog, inputDir, configDir)
+		require.ErrorContains(t, err, "only one of the")
 	})
 
 	t.Run("missing file == skip", func(t *testing.T) {
@@ -50,3 +109,52 @@ func setupFs(t *testing.T, inputDir, value string) {
 
 	require.NoError(t, fsutils.CreateFile(filepath.Join(inputDir, InputFileName), value))
 }
+
+func TestValidateInput(t *testing.T) {
+	require.NoError(t, ValidateInput([]byte("123")))
+	require.NoError(t, ValidateInput([]byte(" 0\n")))
+	require.ErrorContains(t, ValidateInput([]byte("12s")), "must be an integer")
+	require.ErrorContains(t, ValidateInput([]byte("")), "must be an integer")
+	require.ErrorContains(t, ValidateInput([]byte("-1")), "must not be negative")
+}
+
+func TestParseOptions(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		options, err := ParseOptions([]byte("initialConnectRetryMs: 5000\nproxyType: http\nverbose: false\nretries: 3\n"))
+		require.NoError(t, err)
+		assert.Equal(t, Options{
+			"initialConnectRetryMs": "5000",
+			"proxyType":             "http",
+			"verbose":               "false",
+			"retries":               "3",
+		}, options)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		options, err := ParseOptions([]byte(""))
+		require.NoError(t, err)
+		assert.Empty(t, options)
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, err := ParseOptions([]byte("[1, 2]"))
+		require.ErrorContains(t, err, "must be an object of the options by name")
+	})
+
+	t.Run("invalid options are reported at once", func(t *testing.T) {
+		_, err := ParseOptions([]byte(`{
+			"initialConnectRetryMs": "5000",
+			"connectTimeoutMs": -1,
+			"retries": 1.5,
+			"proxyType": "http\ninjected 1",
+			"name": "",
+			"bad name": 1
+		}`))
+		require.ErrorContains(t, err, "initialConnectRetryMs: must be a non-negative integer (milliseconds)")
+		require.ErrorContains(t, err, "connectTimeoutMs: must be a non-negative integer (milliseconds)")
+		require.ErrorContains(t, err, "retries: must be an integer, a boolean or a string")
+		require.ErrorContains(t, err, "proxyType: must be a non-empty single-line string")
+		require.ErrorContains(t, err, "name: must be a non-empty single-line string")
+		require.ErrorContains(t, err, `"bad name": must be an alphanumeric option name`)
+	})
+}
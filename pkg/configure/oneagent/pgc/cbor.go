@@ -0,0 +1,249 @@
+package pgc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// CBOR major types, see RFC 8949 section 3.1.
+const (
+	majorUnsigned = iota
+	majorNegative
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+const (
+	majorTypeShift = 5
+	additionalMask = 0x1f
+	breakCode      = 0xff
+
+	additionalOneByte    = 24
+	additionalTwoBytes   = 25
+	additionalFourBytes  = 26
+	additionalEightBytes = 27
+	additionalIndefinite = 31
+
+	// a map consists of key-value pairs
+	itemsPerMapEntry = 2
+
+	// simple values below 32 must be encoded directly in the initial byte
+	minTwoByteSimpleValue = 32
+
+	// maxNestingDepth protects against a stack overflow caused by malicious input
+	maxNestingDepth = 256
+)
+
+var errBreak = errors.New("unexpected break")
+
+// ValidateInput checks that the content of the input file is a single well-formed CBOR data item (RFC 8949 appendix C).
+// It does not check the validity of the content, e.g. the UTF-8 encoding of text strings or the semantics of tags.
+func ValidateInput(raw []byte) error {
+	if len(raw) == 0 {
+		return errors.New("must not be empty")
+	}
+
+	decoder := cborDecoder{data: raw}
+	if err := decoder.item(0); err != nil {
+		return fmt.Errorf("malformed CBOR at offset %d: %w", decoder.offset, err)
+	}
+
+	if decoder.offset != len(raw) {
+		return fmt.Errorf("malformed CBOR at offset %d: unexpected data after the first data item", decoder.offset)
+	}
+
+	return nil
+}
+
+// cborDecoder skips over CBOR data items, without decoding their values.
+type cborDecoder struct {
+	data   []byte
+	offset int
+}
+
+// item skips a single data item, it returns errBreak if the item is a "break" stop code.
+func (d *cborDecoder) item(depth int) error {
+	if depth > maxNestingDepth {
+		return fmt.Errorf("nesting depth exceeds %d", maxNestingDepth)
+	}
+
+	major, additional, err := d.head()
+	if err != nil {
+		return err
+	}
+
+	if additional == additionalIndefinite {
+		return d.indefinite(major, depth)
+	}
+
+	argument, err := d.argument(additional)
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case majorUnsigned, majorNegative:
+		return nil
+	case majorBytes, majorText:
+		return d.skip(argument)
+	case majorArray:
+		return d.items(argument, depth)
+	case majorMap:
+		if argument > uint64(len(d.data)) {
+			return errors.New("map length exceeds the remaining data")
+		}
+
+		return d.items(itemsPerMapEntry*argument, depth)
+	case majorTag:
+		// a tag is followed by exactly one data item
+		if err := d.nested(depth); errors.Is(err, errBreak) {
+			return errors.New("unexpected break after a tag")
+		} else if err != nil {
+			return err
+		}
+
+		return nil
+	default: // majorSimple
+		if additional == additionalOneByte && argument < minTwoByteSimpleValue {
+			return fmt.Errorf("simple value %d must not use a two-byte encoding", argument)
+		}
+
+		return nil
+	}
+}
+
+// head reads the initial byte of a data item.
+func (d *cborDecoder) head() (major, additional byte, err error) {
+	if d.offset >= len(d.data) {
+		return 0, 0, errors.New("unexpected end of data")
+	}
+
+	initial := d.data[d.offset]
+	d.offset++
+
+	return initial >> majorTypeShift, initial & additionalMask, nil
+}
+
+// argument reads the argument of a data item, which follows the initial byte depending on the additional information.
+func (d *cborDecoder) argument(additional byte) (uint64, error) {
+	if additional < additionalOneByte {
+		return uint64(additional), nil
+	}
+
+	if additional > additionalEightBytes {
+		return 0, fmt.Errorf("reserved additional information %d", additional)
+	}
+
+	// the argument is encoded in the following 1, 2, 4 or 8 bytes
+	size := 1 << (additional - additionalOneByte)
+	if d.offset+size > len(d.data) {
+		return 0, errors.New("unexpected end of data")
+	}
+
+	raw := d.data[d.offset : d.offset+size]
+	d.offset += size
+
+	switch additional {
+	case additionalOneByte:
+		return uint64(raw[0]), nil
+	case additionalTwoBytes:
+		return uint64(binary.BigEndian.Uint16(raw)), nil
+	case additionalFourBytes:
+		return uint64(binary.BigEndian.Uint32(raw)), nil
+	default:
+		return binary.BigEndian.Uint64(raw), nil
+	}
+}
+
+// indefinite skips the content of an indefinite-length data item, up to and including its "break" stop code.
+func (d *cborDecoder) indefinite(major byte, depth int) error {
+	switch major {
+	case majorBytes, majorText:
+		// the chunks must be definite-length strings of the same major type
+		for {
+			if d.offset < len(d.data) && d.data[d.offset] == breakCode {
+				d.offset++
+
+				return nil
+			}
+
+			chunkMajor, additional, err := d.head()
+			if err != nil {
+				return err
+			}
+
+			if chunkMajor != major || additional == additionalIndefinite {
+				return errors.New("invalid chunk in an indefinite-length string")
+			}
+
+			length, err := d.argument(additional)
+			if err != nil {
+				return err
+			}
+
+			if err := d.skip(length); err != nil {
+				return err
+			}
+		}
+	case majorArray, majorMap:
+		count := 0
+
+		for {
+			err := d.nested(depth)
+			if errors.Is(err, errBreak) {
+				if major == majorMap && count%itemsPerMapEntry != 0 {
+					return errors.New("missing value in an indefinite-length map")
+				}
+
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			count++
+		}
+	case majorSimple:
+		return errBreak
+	default:
+		return fmt.Errorf("major type %d must not have an indefinite length", major)
+	}
+}
+
+// items skips the given number of nested data items.
+func (d *cborDecoder) items(count uint64, depth int) error {
+	// every data item has at least one byte, so a larger count can't be valid
+	if count > uint64(len(d.data)-d.offset) {
+		return errors.New("length exceeds the remaining data")
+	}
+
+	for range count {
+		if err := d.nested(depth); err != nil {
+			if errors.Is(err, errBreak) {
+				return errors.New("unexpected break in a definite-length item")
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *cborDecoder) nested(depth int) error {
+	return d.item(depth + 1)
+}
+
+func (d *cborDecoder) skip(length uint64) error {
+	if length > uint64(len(d.data)-d.offset) {
+		return errors.New("length exceeds the remaining data")
+	}
+
+	d.offset += int(length) //nolint:gosec // length is at most the remaining data
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package pgc
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateInput(t *testing.T) {
+	// examples of RFC 8949 appendix A and G
+	wellFormed := map[string]string{
+		"unsigned":                    "00",
+		"large unsigned":              "1bffffffffffffffff",
+		"negative":                    "3903e7",
+		"float":                       "fb3ff199999999999a",
+		"simple":                      "f8ff",
+		"text":                        "6449455446",
+		"bytes":                       "4401020304",
+		"indefinite bytes":            "5f42010243030405ff",
+		"indefinite text":             "7f657374726561646d696e67ff",
+		"array":                       "83010203",
+		"nested array":                "8301820203820405",
+		"indefinite array":            "9f018202039f0405ffff",
+		"map":                         "a201020304",
+		"indefinite map":              "bf61610161629f0203ffff",
+		"tag":                         "c074323031332d30332d32315432303a30343a30305a",
+		"empty indefinite array":      "9fff",
+		"array of 25 items":           "98190102030405060708090a0b0c0d0e0f101112131415161718181819",
+		"map with array value":        "a26161016162820203",
+		"indefinite in definite item": "826161bf61626163ff",
+	}
+
+	for name, input := range wellFormed {
+		t.Run("well-formed "+name, func(t *testing.T) {
+			raw, err := hex.DecodeString(input)
+			require.NoError(t, err)
+			assert.NoError(t, ValidateInput(raw))
+		})
+	}
+
+	malformed := map[string]string{
+		"empty":                          "",
+		"missing argument":               "18",
+		"truncated string":               "62",
+		"reserved additional info":       "1c",
+		"truncated array":                "8301",
+		"missing map value":              "a1",
+		"unexpected break":               "ff",
+		"break in definite array":        "81ff",
+		"indefinite unsigned":            "1f",
+		"indefinite tag":                 "df",
+		"wrong chunk type":               "5f6100ff",
+		"indefinite chunk":               "5f5fffff",
+		"missing break":                  "9f01",
+		"odd indefinite map":             "bf01ff",
+		"two-byte simple value below 32": "f818",
+		"tag with break":                 "9fc0ffff",
+		"trailing data":                  "0000",
+		"huge array length":              "9bffffffffffffffff",
+	}
+
+	for name, input := range malformed {
+		t.Run("malformed "+name, func(t *testing.T) {
+			raw, err := hex.DecodeString(input)
+			require.NoError(t, err)
+			assert.Error(t, ValidateInput(raw))
+		})
+	}
+
+	t.Run("too deeply nested", func(t *testing.T) {
+		raw := make([]byte, maxNestingDepth+2)
+		for i := range raw {
+			raw[i] = 0x81
+		}
+
+		raw[len(raw)-1] = 0x00
+
+		require.ErrorContains(t, ValidateInput(raw), "nesting depth")
+	})
+}
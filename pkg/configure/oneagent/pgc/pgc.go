@@ -1,6 +1,7 @@
 package pgc
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -37,5 +38,5 @@ func Configure(log logr.Logger, inputDir, containerConfigDir string) error {
 
 	log.Info("copying declarative.cbor", "src", inputFilePath, "dst", dstPath)
 
-	return fs.CopyFile(inputFilePath, dstPath)
+	return fs.CopyFile(context.Background(), inputFilePath, dstPath)
 }
@@ -10,11 +10,21 @@ import (
 )
 
 func Create(log logr.Logger, srcPath, dstPath string, conf ruxit.ProcConf) error {
+	content, err := merge(log, srcPath, conf)
+	if err != nil {
+		return err
+	}
+
+	return fs.CreateReadOnlyFile(dstPath, content)
+}
+
+// merge returns the source ruxitagentproc.conf, merged with the given conf.
+func merge(log logr.Logger, srcPath string, conf ruxit.ProcConf) (string, error) {
 	srcFile, err := os.Open(srcPath)
 	if err != nil {
 		log.Info("failed to open source file", "path", srcPath)
 
-		return errors.WithStack(err)
+		return "", errors.WithStack(err)
 	}
 
 	defer func() { _ = srcFile.Close() }()
@@ -23,10 +33,8 @@ func Create(log logr.Logger, srcPath, dstPath string, conf ruxit.ProcConf) error
 	if err != nil {
 		log.Info("failed to parse source file to struct", "path", srcPath)
 
-		return err
+		return "", err
 	}
 
-	mergedConf := srcConf.Merge(conf)
-
-	return fs.CreateReadOnlyFile(dstPath, mergedConf.ToString())
+	return srcConf.Merge(conf).ToString(), nil
 }
@@ -1,10 +1,14 @@
 package pmc
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc/ruxit"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/proxy"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs"
 	"github.com/go-logr/logr"
 )
 
@@ -23,20 +27,76 @@ func GetDestinationRuxitAgentProcFilePath(configDir string) string {
 	return filepath.Join(configDir, DestinationRuxitAgentProcPath)
 }
 
+// Conf is the ruxitagentproc.conf of the containers, which is the same for all containers of a pod, so it is only parsed once per pod.
+type Conf struct {
+	content string
+	// skip is true if the input file is not present
+	skip bool
+}
+
 func Configure(log logr.Logger, inputDir, targetDir, configDir, installPath string) error {
+	conf, err := Parse(log, inputDir, targetDir, installPath)
+	if err != nil {
+		return err
+	}
+
+	return conf.Write(log, configDir)
+}
+
+// Parse merges the input file and the proxy input file into the source ruxitagentproc.conf of the CodeModule in the targetDir.
+// The proxy takes precedence over the input file, the configuration is skipped if neither of them is present.
+func Parse(log logr.Logger, inputDir, targetDir, installPath string) (Conf, error) {
+	conf, err := readInputFile(log, inputDir)
+	if err != nil && !os.IsNotExist(err) {
+		return Conf{}, err
+	}
+
+	inputFound := err == nil
+
+	proxyConf, err := proxy.Read(inputDir)
+	if err != nil && !os.IsNotExist(err) {
+		log.Info("failed to parse the proxy input file", "path", filepath.Join(inputDir, proxy.InputFileName))
+
+		return Conf{}, err
+	}
+
+	proxyFound := err == nil
+
+	if !inputFound && !proxyFound {
+		log.Info("Input file not present, skipping ruxitagentproc.conf configuration", "path", filepath.Join(inputDir, InputFileName))
+
+		return Conf{skip: true}, nil
+	}
+
+	if proxyFound {
+		log.Info("configuring the proxy", "url", proxyConf.Redacted(), "no-proxy", strings.Join(proxyConf.NoProxy, ","))
+
+		conf.Properties = append(conf.Properties, proxyConf.Properties()...)
+	}
+
+	conf.InstallPath = &installPath
+
+	srcPath := GetSourceRuxitAgentProcFilePath(targetDir)
+
+	content, err := merge(log, srcPath, conf)
+	if err != nil {
+		return Conf{}, err
+	}
+
+	return Conf{content: content}, nil
+}
+
+// readInputFile parses the input file, the error of a missing input file satisfies os.IsNotExist.
+func readInputFile(log logr.Logger, inputDir string) (ruxit.ProcConf, error) {
 	inputFilePath := filepath.Join(inputDir, InputFileName)
 
 	inputFile, err := os.Open(inputFilePath)
 	if err != nil {
-		if os.IsNotExist(err) {
-			log.Info("Input file not present, skipping ruxitagentproc.conf configuration", "path", inputFilePath)
-
-			return nil
+		if !os.IsNotExist(err) {
+			log.Info("failed to input file", "path", inputFilePath)
 		}
 
-		log.Info("failed to input file", "path", inputFilePath)
-
-		return err
+		return ruxit.ProcConf{}, err
 	}
 
 	defer func() { _ = inputFile.Close() }()
@@ -45,15 +105,28 @@ func Configure(log logr.Logger, inputDir, targetDir, configDir, installPath stri
 	if err != nil {
 		log.Info("failed to unmarshal the input file", "path", inputFilePath)
 
-		return err
+		return ruxit.ProcConf{}, err
 	}
 
-	conf.InstallPath = &installPath
+	return conf, nil
+}
+
+// Write creates the ruxitagentproc.conf in the config directory of a container.
+func (c Conf) Write(log logr.Logger, configDir string) error {
+	if c.skip {
+		return nil
+	}
 
-	srcPath := GetSourceRuxitAgentProcFilePath(targetDir)
 	dstPath := GetDestinationRuxitAgentProcFilePath(configDir)
 
-	log.Info("creating ruxitagentproc.conf", "source", srcPath, "destination", dstPath)
+	log.Info("creating ruxitagentproc.conf", "destination", dstPath)
+
+	return fs.CreateReadOnlyFile(dstPath, c.content)
+}
+
+// ValidateInput checks that the content of the input file can be parsed, the same way as during the configuration.
+func ValidateInput(raw []byte) error {
+	_, err := ruxit.FromJSON(bytes.NewReader(raw))
 
-	return Create(log, srcPath, dstPath, conf)
+	return err
 }
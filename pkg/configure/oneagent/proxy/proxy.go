@@ -0,0 +1,139 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/configure/oneagent/pmc/ruxit"
+)
+
+const (
+	InputFileName = "proxy"
+
+	URLProperty     = "url"
+	NoProxyProperty = "no-proxy"
+
+	// the proxy is configured in the general section of the ruxitagentproc.conf
+	procSection    = "general"
+	procProxyKey   = "proxy"
+	procNoProxyKey = "noProxy"
+
+	redactedCredentials = "xxxxx"
+)
+
+// Proxy is the HTTP proxy, which the CodeModule uses for its communication.
+type Proxy struct {
+	// URL may contain credentials, only log the Redacted URL
+	URL     *url.URL
+	NoProxy []string
+}
+
+// Read parses the input file, the error of a missing input file satisfies os.IsNotExist.
+func Read(inputDir string) (Proxy, error) {
+	raw, err := os.ReadFile(filepath.Join(inputDir, InputFileName))
+	if err != nil {
+		return Proxy{}, err
+	}
+
+	return Parse(raw)
+}
+
+// Parse parses the content of the input file, which has a `url` and an optional `no-proxy` (comma-separated hosts) in key=value format.
+// The errors never contain the URL, as it may contain credentials.
+func Parse(raw []byte) (Proxy, error) {
+	properties := map[string]string{}
+
+	for i, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return Proxy{}, fmt.Errorf("line %d: must be in key=value format", i+1)
+		}
+
+		key = strings.TrimSpace(key)
+		if key != URLProperty && key != NoProxyProperty {
+			// the key is left out, as it may be a mistyped URL with credentials
+			return Proxy{}, fmt.Errorf("line %d: unknown property, must be %q or %q", i+1, URLProperty, NoProxyProperty)
+		}
+
+		properties[key] = strings.TrimSpace(value)
+	}
+
+	proxyURL, err := parseURL(properties[URLProperty])
+	if err != nil {
+		return Proxy{}, err
+	}
+
+	var noProxy []string
+
+	for host := range strings.SplitSeq(properties[NoProxyProperty], ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			noProxy = append(noProxy, host)
+		}
+	}
+
+	return Proxy{URL: proxyURL, NoProxy: noProxy}, nil
+}
+
+func parseURL(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("%s: must not be empty", URLProperty)
+	}
+
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		// the url.Error contains the whole URL, including the credentials
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+
+		return nil, fmt.Errorf("%s: %w", URLProperty, err)
+	}
+
+	if (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") || proxyURL.Host == "" {
+		return nil, fmt.Errorf("%s: must be an absolute http(s) URL", URLProperty)
+	}
+
+	return proxyURL, nil
+}
+
+// Redacted returns the URL with the credentials replaced, so it can be logged.
+func (p Proxy) Redacted() string {
+	if p.URL.User == nil {
+		return p.URL.String()
+	}
+
+	redacted := *p.URL
+	redacted.User = url.User(redactedCredentials)
+
+	return redacted.String()
+}
+
+// Properties returns the properties of the ruxitagentproc.conf, which configure the proxy of the CodeModule.
+func (p Proxy) Properties() []ruxit.Property {
+	properties := []ruxit.Property{
+		{Section: procSection, Key: procProxyKey, Value: p.URL.String()},
+	}
+
+	if len(p.NoProxy) > 0 {
+		properties = append(properties, ruxit.Property{Section: procSection, Key: procNoProxyKey, Value: strings.Join(p.NoProxy, ",")})
+	}
+
+	return properties
+}
+
+// ValidateInput checks that the content of the input file has a valid proxy URL.
+func ValidateInput(raw []byte) error {
+	_, err := Parse(raw)
+
+	return err
+}
@@ -0,0 +1,45 @@
+package deployment
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/journal"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/lock"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
+	"github.com/go-logr/logr"
+)
+
+// HistoryFilePath is the path of the deployment history journal, relative to the target base folder.
+const HistoryFilePath = "deployment-history.jsonl"
+
+// NewHistory returns the deployment history journal in the given target base folder.
+func NewHistory(targetBaseFolder string) *journal.Journal {
+	return journal.New(filepath.Join(targetBaseFolder, HistoryFilePath))
+}
+
+// recordDeployment appends the record with the outcome of the given deployment error to the history journal.
+// The journal is only written while holding the deployment lock, so there is only one writer at a time.
+// A failure to write the journal does not fail the deployment.
+func recordDeployment(ctx context.Context, logger logr.Logger, fileLock *lock.FileLock, targetBaseFolder string, record journal.Record, deployErr error) {
+	switch {
+	case deployErr == nil:
+		record.Outcome = journal.OutcomeSuccess
+	case ctx.Err() != nil:
+		record.Outcome = journal.OutcomeAborted
+		record.Error = deployErr.Error()
+	default:
+		record.Outcome = journal.OutcomeFailure
+		record.Error = deployErr.Error()
+	}
+
+	if err := fileLock.Validate(); err != nil {
+		log.Debug(logger, "Deployment lock lost, not recording the deployment in the history", "reason", err.Error())
+
+		return
+	}
+
+	if err := NewHistory(targetBaseFolder).Append(record); err != nil {
+		logger.Error(err, "failed to record the deployment in the history", "path", filepath.Join(targetBaseFolder, HistoryFilePath))
+	}
+}
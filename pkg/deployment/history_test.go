@@ -0,0 +1,103 @@
+package deployment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/journal"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploymentHistory(t *testing.T) {
+	const (
+		agentVersion1 = "1.325.22.20251002-101422"
+		agentVersion2 = "1.327.30.20251107-111521"
+	)
+
+	t.Run("every deployment is recorded", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceBaseDir := t.TempDir()
+		targetBaseDir := t.TempDir()
+		workBaseDir := t.TempDir()
+
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion1)
+
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue, WithInstanceID("instance-1"))
+		require.NoError(t, err)
+		require.True(t, deployed)
+
+		// already deployed ==> nothing recorded
+		deployed, err = DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue, WithInstanceID("instance-2"))
+		require.NoError(t, err)
+		require.False(t, deployed)
+
+		sourceBaseDir = t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion2)
+
+		deployed, err = DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, "", WithInstanceID("instance-3"))
+		require.NoError(t, err)
+		require.True(t, deployed)
+
+		records, err := NewHistory(targetBaseDir).Read()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+
+		assert.Equal(t, "instance-1", records[0].Instance)
+		assert.Equal(t, agentVersion1, records[0].Version)
+		assert.Empty(t, records[0].Technologies)
+		assert.Equal(t, journal.OutcomeSuccess, records[0].Outcome)
+		assert.Empty(t, records[0].PreviousActive)
+		assert.NotEmpty(t, records[0].Duration)
+		assert.False(t, records[0].Timestamp.IsZero())
+
+		assert.Equal(t, "instance-3", records[1].Instance)
+		assert.Equal(t, agentVersion2, records[1].Version)
+		assert.Empty(t, records[1].Technologies)
+		assert.Equal(t, agentVersion1, records[1].PreviousActive)
+	})
+
+	t.Run("failed deployment is recorded", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		// the source has no manifest.json, which is needed to copy specific technologies
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion1)
+
+		targetBaseDir := t.TempDir()
+		_, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, t.TempDir(), "java,nodejs", WithInstanceID("instance-1"))
+		require.Error(t, err)
+
+		records, err := NewHistory(targetBaseDir).Read()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		assert.Equal(t, journal.OutcomeFailure, records[0].Outcome)
+		assert.Equal(t, "java,nodejs", records[0].Technologies)
+		assert.Contains(t, records[0].Error, "manifest.json")
+	})
+
+	t.Run("aborted deployment is recorded", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion1)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		targetBaseDir := t.TempDir()
+		_, err := DeployOneAgent(ctx, logger, sourceBaseDir, targetBaseDir, t.TempDir(), allTechValue)
+		require.Error(t, err)
+
+		records, err := NewHistory(targetBaseDir).Read()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+
+		assert.Equal(t, journal.OutcomeAborted, records[0].Outcome)
+		assert.Contains(t, records[0].Error, "context canceled")
+		assert.NotEmpty(t, records[0].Instance)
+	})
+}
@@ -0,0 +1,182 @@
+package deployment
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/lock"
+	"github.com/go-logr/logr"
+)
+
+// DeployedVersion is a versioned OneAgent folder in the target directory.
+type DeployedVersion struct {
+	// Version is the name of the versioned OneAgent folder, i.e. <version> or <version>-<suffix>.
+	Version string
+	// Technologies is the comma-separated list of the deployed technologies, empty if all technologies are deployed.
+	Technologies string
+	Arch         string
+	// Size is the total size of the regular files in the folder, in bytes.
+	Size int64
+	// Complete is true if the completion marker of the folder matches its content, including the content of the files.
+	Complete bool
+}
+
+// Report extends the AgentDeploymentInfo with details about the target and work directories.
+type Report struct {
+	AgentDeploymentInfo
+
+	// ActiveLinkTarget is empty if the `active` symlink does not exist.
+	ActiveLinkTarget string
+	DeployedVersions []DeployedVersion
+	Lock             lock.State
+	// StaleWorkFolders are the copy work folders which are not used by an ongoing deployment,
+	// i.e. left behind by an instance which died during the deployment.
+	StaleWorkFolders []string
+}
+
+// Inspect checks the OneAgent deployment status (see CheckAgentDeploymentStatus) and collects the details of the target and work directories, without changing them.
+// The returned error joins all errors which occurred while collecting the details, the Report contains all details that could be collected.
+// The error of the deployment status check is part of the AgentDeploymentInfo, just like for CheckAgentDeploymentStatus.
+func Inspect(logger logr.Logger, sourceBaseFolder, targetBaseFolder, workBaseFolder, technology string) (Report, error) {
+	report := Report{
+		AgentDeploymentInfo: CheckAgentDeploymentStatus(sourceBaseFolder, targetBaseFolder, technology),
+	}
+
+	var errs []error
+
+	activeLinkTarget, err := os.Readlink(filepath.Join(targetBaseFolder, ActiveLinkPath))
+	if err != nil && !os.IsNotExist(err) {
+		errs = append(errs, fmt.Errorf("cannot read OneAgent `active` symlink: %w", err))
+	}
+
+	report.ActiveLinkTarget = activeLinkTarget
+
+	report.DeployedVersions, err = getDeployedVersions(targetBaseFolder, workBaseFolder)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	report.Lock, err = lock.New(logger, getPathToDeploymentLockFile(workBaseFolder)).Inspect()
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	report.StaleWorkFolders, err = getStaleWorkFolders(workBaseFolder, report.Lock)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// getDeployedVersions returns the versioned OneAgent folders in the target directory, the `active` symlink is not included.
+// The work directory is skipped, as it defaults to a folder next to the versioned OneAgent folders (e.g. /home/dynatrace/oneagent/work).
+func getDeployedVersions(targetBaseFolder, workBaseFolder string) ([]DeployedVersion, error) {
+	agentsFolder := filepath.Dir(filepath.Join(targetBaseFolder, ActiveLinkPath))
+
+	entries, err := os.ReadDir(agentsFolder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cannot list the deployed OneAgent versions: %w", err)
+	}
+
+	var versions []DeployedVersion
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		agentFolder := filepath.Join(agentsFolder, entry.Name())
+		if agentFolder == filepath.Clean(workBaseFolder) {
+			continue
+		}
+
+		size, err := getFolderSize(agentFolder)
+		if err != nil {
+			return versions, fmt.Errorf("cannot determine the size of OneAgent version %s: %w", entry.Name(), err)
+		}
+
+		state, err := marker.Verify(agentFolder)
+		if err != nil {
+			return versions, fmt.Errorf("cannot verify OneAgent version %s: %w", entry.Name(), err)
+		}
+
+		versions = append(versions, DeployedVersion{
+			Version:      entry.Name(),
+			Technologies: state.Selection.Technologies,
+			Arch:         state.Selection.Arch,
+			Size:         size,
+			Complete:     state.Complete,
+		})
+	}
+
+	return versions, nil
+}
+
+func getFolderSize(folder string) (int64, error) {
+	var size int64
+
+	err := filepath.WalkDir(folder, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		size += info.Size()
+
+		return nil
+	})
+
+	return size, err
+}
+
+// getStaleWorkFolders returns the copy work folders which are not used by an ongoing deployment.
+// A work folder can only be in use if the deployment lock is held, is not stale and was created after the work folder.
+func getStaleWorkFolders(workBaseFolder string, lockState lock.State) ([]string, error) {
+	entries, err := os.ReadDir(workBaseFolder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("cannot list the work folders: %w", err)
+	}
+
+	var staleFolders []string
+
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), copyWorkFolderPrefix) {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return staleFolders, fmt.Errorf("cannot obtain work folder info: %w", err)
+		}
+
+		inUse := lockState.Held && !lockState.Stale && time.Since(info.ModTime()) <= lockState.Age
+		if !inUse {
+			staleFolders = append(staleFolders, filepath.Join(workBaseFolder, entry.Name()))
+		}
+	}
+
+	return staleFolders, nil
+}
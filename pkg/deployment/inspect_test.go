@@ -0,0 +1,124 @@
+package deployment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/lock"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	const (
+		agentVersion = "1.327.30.20251107-111521"
+		oldVersion   = "1.325.51.20251103-195814"
+	)
+
+	t.Run("deployed versions and active link target", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, oldVersion, "")
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+		require.NoError(t, os.WriteFile(filepath.Join(GetAgentFolder(targetDir, oldVersion), "file"), []byte("12345"), 0o600))
+
+		report, err := Inspect(logger, sourceDir, targetDir, t.TempDir(), allTechValue)
+		require.NoError(t, err)
+
+		assert.Equal(t, Deployed, report.Status)
+		assert.Equal(t, agentVersion, report.AgentVersion)
+		assert.Equal(t, agentVersion, report.ActiveLinkTarget)
+		require.Len(t, report.DeployedVersions, 2)
+		assert.Equal(t, oldVersion, report.DeployedVersions[0].Version)
+		assert.False(t, report.DeployedVersions[0].Complete, "file was added after the completion marker")
+		assert.Equal(t, agentVersion, report.DeployedVersions[1].Version)
+		assert.True(t, report.DeployedVersions[1].Complete)
+		// the file and the completion marker
+		assert.Equal(t, report.DeployedVersions[1].Size+5, report.DeployedVersions[0].Size)
+		assert.False(t, report.Lock.Held)
+		assert.Empty(t, report.StaleWorkFolders)
+	})
+
+	t.Run("work folder next to the deployed versions is not a version", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		targetDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetDir, agentVersion, agentVersion)
+
+		workDir := filepath.Join(filepath.Dir(GetAgentFolder(targetDir, agentVersion)), "work")
+		require.NoError(t, os.MkdirAll(workDir, 0o755))
+
+		report, err := Inspect(logger, sourceDir, targetDir, workDir+"/", allTechValue)
+		require.NoError(t, err)
+
+		require.Len(t, report.DeployedVersions, 1)
+		assert.Equal(t, agentVersion, report.DeployedVersions[0].Version)
+	})
+
+	t.Run("nothing deployed, no work folder", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		report, err := Inspect(logger, sourceDir, t.TempDir(), filepath.Join(t.TempDir(), "work"), allTechValue)
+		require.NoError(t, err)
+
+		assert.Equal(t, NotDeployed, report.Status)
+		assert.Empty(t, report.ActiveLinkTarget)
+		assert.Empty(t, report.DeployedVersions)
+	})
+
+	t.Run("work folders of a dead deployment are stale", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceDir, agentVersion)
+
+		workDir := t.TempDir()
+		staleWorkFolder := filepath.Join(workDir, copyWorkFolderPrefix+"123")
+		require.NoError(t, os.Mkdir(staleWorkFolder, 0o700))
+
+		report, err := Inspect(logger, sourceDir, t.TempDir(), workDir, allTechValue)
+		require.NoError(t, err)
+		assert.Equal(t, []string{staleWorkFolder}, report.StaleWorkFolders)
+
+		// a work folder created after the lock belongs to the ongoing deployment
+		fileLock := lock.New(logger, getPathToDeploymentLockFile(workDir))
+		acquired, err := fileLock.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		past := time.Now().Add(-time.Minute)
+		require.NoError(t, os.Chtimes(getPathToDeploymentLockFile(workDir), past, past))
+		require.NoError(t, os.Chtimes(staleWorkFolder, past.Add(-time.Minute), past.Add(-time.Minute)))
+
+		ongoingWorkFolder := filepath.Join(workDir, copyWorkFolderPrefix+"456")
+		require.NoError(t, os.Mkdir(ongoingWorkFolder, 0o700))
+
+		report, err = Inspect(logger, sourceDir, t.TempDir(), workDir, allTechValue)
+		require.NoError(t, err)
+		assert.True(t, report.Lock.Held)
+		assert.Equal(t, fileLock.Token(), report.Lock.Token)
+		assert.Equal(t, []string{staleWorkFolder}, report.StaleWorkFolders)
+	})
+
+	t.Run("status error is part of the report", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		report, err := Inspect(logger, t.TempDir(), t.TempDir(), t.TempDir(), allTechValue)
+		require.NoError(t, err)
+		assert.Equal(t, Unknown, report.Status)
+		require.Error(t, report.Error)
+	})
+}
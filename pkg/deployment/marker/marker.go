@@ -0,0 +1,215 @@
+package marker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// FileName is the name of the completion marker file in the versioned OneAgent folder.
+const FileName = ".dt-deployment-complete"
+
+const filePerm644 fs.FileMode = 0o644
+
+// Selection describes which part of the OneAgent has been deployed into a folder.
+type Selection struct {
+	// Technologies is the normalized, comma-separated list of the deployed technologies, empty if all technologies are deployed.
+	Technologies string `json:"technologies,omitempty"`
+	Arch         string `json:"arch,omitempty"`
+}
+
+// State is the state of a folder according to its completion marker.
+type State struct {
+	// Selection is empty if the marker is missing or malformed.
+	Selection Selection
+	// Complete is true if the marker exists and matches the content of the folder.
+	Complete bool
+}
+
+// content is the content of the completion marker file.
+type content struct {
+	Selection
+
+	// Hash is the SHA-256 of the relative path, type and file content (or link target) of every entry in the folder.
+	// It is compared by Verify, as reading every file is too expensive for the repeated status checks.
+	Hash string `json:"hash"`
+	// Layout is the SHA-256 of the relative path, type and size (or link target) of every entry in the folder, it is compared by Read and Verify.
+	Layout string `json:"layout"`
+	Files  int    `json:"files"`
+	Size   int64  `json:"size"`
+}
+
+// Write creates the completion marker in the given folder, with the hashes of its current content and the deployed selection.
+// It must be the last change to the folder, any later change makes the marker invalid.
+func Write(folder string, selection Selection) error {
+	marker, err := hashFolder(folder, true)
+	if err != nil {
+		return fmt.Errorf("failed to hash the folder %s: %w", folder, err)
+	}
+
+	marker.Selection = selection
+
+	raw, err := json.Marshal(marker)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the completion marker: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(folder, FileName), raw, filePerm644); err != nil {
+		return fmt.Errorf("failed to write the completion marker: %w", err)
+	}
+
+	return nil
+}
+
+// Read returns the state of the given folder according to its completion marker.
+// The layout of the folder (names, types and sizes of all entries) is compared to the marker, the content of the files is not read.
+// Returns an error only if the folder can't be read, fs.ErrNotExist if it does not exist.
+func Read(folder string) (State, error) {
+	return read(folder, false)
+}
+
+// Verify is like Read, but also compares the content of the files to the marker, so it detects changes that keep the size of a file.
+func Verify(folder string) (State, error) {
+	return read(folder, true)
+}
+
+func read(folder string, withContent bool) (State, error) {
+	if _, err := os.Stat(folder); err != nil {
+		return State{}, err
+	}
+
+	raw, err := os.ReadFile(filepath.Join(folder, FileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return State{}, nil
+		}
+
+		return State{}, fmt.Errorf("failed to read the completion marker: %w", err)
+	}
+
+	var expected content
+	if err := json.Unmarshal(raw, &expected); err != nil {
+		// a malformed marker is as good as a missing one
+		return State{}, nil //nolint:nilerr
+	}
+
+	actual, err := hashFolder(folder, withContent)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to hash the folder %s: %w", folder, err)
+	}
+
+	complete := actual.Layout == expected.Layout && actual.Files == expected.Files && actual.Size == expected.Size
+	if withContent {
+		complete = complete && actual.Hash == expected.Hash
+	}
+
+	return State{
+		Selection: expected.Selection,
+		Complete:  complete,
+	}, nil
+}
+
+// hashFolder walks the folder once, the hash of the file contents is only computed if withContent is set.
+func hashFolder(folder string, withContent bool) (content, error) {
+	var result content
+
+	layoutHasher := sha256.New()
+
+	var contentHasher hash.Hash
+	if withContent {
+		contentHasher = sha256.New()
+	}
+
+	err := filepath.WalkDir(folder, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+
+		if relPath == "." || relPath == FileName {
+			return nil
+		}
+
+		return hashEntry(layoutHasher, contentHasher, &result, path, relPath, entry)
+	})
+	if err != nil {
+		return content{}, err
+	}
+
+	result.Layout = hex.EncodeToString(layoutHasher.Sum(nil))
+
+	if contentHasher != nil {
+		result.Hash = hex.EncodeToString(contentHasher.Sum(nil))
+	}
+
+	return result, nil
+}
+
+func hashEntry(layoutHasher, contentHasher hash.Hash, result *content, path, relPath string, entry fs.DirEntry) error {
+	var kind, layoutDetail, contentDetail string
+
+	switch {
+	case entry.IsDir():
+		kind = "d"
+	case entry.Type()&fs.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil {
+			return err
+		}
+
+		kind, layoutDetail, contentDetail = "l", target, target
+	default:
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		kind, layoutDetail = "f", strconv.FormatInt(info.Size(), 10)
+		result.Files++
+		result.Size += info.Size()
+
+		if contentHasher != nil {
+			contentDetail, err = hashFile(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// the NUL separator can't be part of a path, so different entries can't produce the same input
+	_, _ = fmt.Fprintf(layoutHasher, "%s\x00%s\x00%s\n", relPath, kind, layoutDetail)
+
+	if contentHasher != nil {
+		_, _ = fmt.Fprintf(contentHasher, "%s\x00%s\x00%s\n", relPath, kind, contentDetail)
+	}
+
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
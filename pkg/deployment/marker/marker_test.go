@@ -0,0 +1,187 @@
+package marker
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupFolder(t *testing.T) string {
+	t.Helper()
+
+	folder := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(folder, "agent", "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(folder, "agent", "bin", "lib.so"), []byte("content"), 0o644))
+	require.NoError(t, os.Symlink("bin", filepath.Join(folder, "agent", "current")))
+
+	return folder
+}
+
+func TestRead(t *testing.T) {
+	t.Run("marker matches the content", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.True(t, state.Complete)
+	})
+
+	t.Run("marker records the selection", func(t *testing.T) {
+		folder := setupFolder(t)
+		selection := Selection{Technologies: "java,nodejs", Arch: "arm64"}
+		require.NoError(t, Write(folder, selection))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.True(t, state.Complete)
+		assert.Equal(t, selection, state.Selection)
+	})
+
+	t.Run("missing folder", func(t *testing.T) {
+		_, err := Read(filepath.Join(t.TempDir(), "missing"))
+		require.ErrorIs(t, err, fs.ErrNotExist)
+	})
+
+	t.Run("missing marker", func(t *testing.T) {
+		state, err := Read(setupFolder(t))
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+
+	t.Run("malformed marker", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, os.WriteFile(filepath.Join(folder, FileName), []byte("{"), 0o644))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+		require.NoError(t, os.Remove(filepath.Join(folder, "agent", "bin", "lib.so")))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+
+	t.Run("truncated file", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+		require.NoError(t, os.WriteFile(filepath.Join(folder, "agent", "bin", "lib.so"), []byte("con"), 0o644))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+
+	t.Run("changed symlink", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+		require.NoError(t, os.Remove(filepath.Join(folder, "agent", "current")))
+		require.NoError(t, os.Symlink("other", filepath.Join(folder, "agent", "current")))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+
+	t.Run("additional file", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+		require.NoError(t, os.WriteFile(filepath.Join(folder, "extra"), []byte{}, 0o644))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+}
+
+func TestReadRepeated(t *testing.T) {
+	t.Run("nested change after a complete read is detected", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		require.True(t, state.Complete)
+
+		// a change deep inside the folder does not change the modification time of the folder
+		require.NoError(t, os.Remove(filepath.Join(folder, "agent", "bin", "lib.so")))
+
+		state, err = Read(folder)
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("matches", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{Arch: "amd64"}))
+
+		state, err := Verify(folder)
+		require.NoError(t, err)
+		assert.True(t, state.Complete)
+		assert.Equal(t, Selection{Arch: "amd64"}, state.Selection)
+	})
+
+	t.Run("changed file of the same size", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+
+		require.NoError(t, os.WriteFile(filepath.Join(folder, "agent", "bin", "lib.so"), []byte("CONTENT"), 0o644))
+
+		state, err := Read(folder)
+		require.NoError(t, err)
+		assert.True(t, state.Complete, "the layout is unchanged")
+
+		state, err = Verify(folder)
+		require.NoError(t, err)
+		assert.False(t, state.Complete)
+	})
+
+	t.Run("missing marker", func(t *testing.T) {
+		folder := setupFolder(t)
+
+		state, err := Verify(folder)
+		require.NoError(t, err)
+		assert.Equal(t, State{}, state)
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("content hash differs for a file of the same size", func(t *testing.T) {
+		folder := setupFolder(t)
+		require.NoError(t, Write(folder, Selection{}))
+		first := readContent(t, folder)
+
+		require.NoError(t, os.WriteFile(filepath.Join(folder, "agent", "bin", "lib.so"), []byte("CONTENT"), 0o644))
+		require.NoError(t, Write(folder, Selection{}))
+		second := readContent(t, folder)
+
+		assert.NotEmpty(t, first.Hash)
+		assert.NotEqual(t, first.Hash, second.Hash)
+		assert.Equal(t, first.Layout, second.Layout)
+	})
+}
+
+func readContent(t *testing.T, folder string) content {
+	t.Helper()
+
+	raw, err := os.ReadFile(filepath.Join(folder, FileName))
+	require.NoError(t, err)
+
+	var result content
+	require.NoError(t, json.Unmarshal(raw, &result))
+
+	return result
+}
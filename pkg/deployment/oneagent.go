@@ -1,11 +1,14 @@
 package deployment
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+	"time"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/journal"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/lock"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/move"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
@@ -15,16 +18,23 @@ import (
 const (
 	allTechValue       = "all" // if set, all technologies will be copied
 	deploymentLockFile = "deployment.lock"
+
+	copyWorkFolderPrefix = "copy-work-"
 )
 
 // DeployOneAgent deploys OneAgent to the target directory using an exclusive file lock to prevent concurrent
 // deployments in multi-instance environments.
 // The lock file is created in the work base folder, ensuring only one instance performs the deployment at a time.
+// If a Configurator is set via WithConfigurator, the OneAgent configuration is rendered while holding the lock as well,
+// also if the agent itself is already deployed but its configuration is missing.
+// Every deployment performed while holding the lock is recorded in the history journal in the target folder.
+//
+// The deployment is aborted when the context is cancelled, the work folder is cleaned up and the lock released.
 //
 // Returns:
 // - bool: true if the OneAgent deployment was performed, false if the deployment was skipped (e.g., OneAgent is already deployed or another instance holds the lock)
 // - error: if deployment fails or an error occurs during the deployment process
-func DeployOneAgent(logger logr.Logger, sourceBaseFolder, targetBaseFolder, workBaseFolder string, technology string) (bool, error) {
+func DeployOneAgent(ctx context.Context, logger logr.Logger, sourceBaseFolder, targetBaseFolder, workBaseFolder string, technology string, opts ...Option) (bool, error) {
 	if err := os.MkdirAll(workBaseFolder, dirPerm755); err != nil {
 		return false, fmt.Errorf("error creating work base folder: %w", err)
 	}
@@ -53,12 +63,17 @@ func DeployOneAgent(logger logr.Logger, sourceBaseFolder, targetBaseFolder, work
 
 	// Before deploying, check the status again in case another Bootstrapper instance
 	// has finished deployment and removed the lock file since the last check.
-	result := CheckAgentDeploymentStatus(sourceBaseFolder, targetBaseFolder)
+	result := CheckAgentDeploymentStatus(sourceBaseFolder, targetBaseFolder, technology)
 	if result.Error != nil {
 		return false, fmt.Errorf("failed to check OneAgent deployment status, skip deployment: %w", result.Error)
 	}
 
-	if result.Status == Deployed {
+	configured, err := IsConfigured(result.AgentVersion, opts...)
+	if err != nil {
+		return false, fmt.Errorf("failed to check OneAgent configuration status, skip deployment: %w", err)
+	}
+
+	if result.Status == Deployed && configured {
 		log.Debug(logger, "OneAgent is already deployed", "OneAgent version", result.AgentVersion)
 
 		return false, nil
@@ -66,19 +81,23 @@ func DeployOneAgent(logger logr.Logger, sourceBaseFolder, targetBaseFolder, work
 
 	logger.Info("The deployment lock file has been acquired. Proceeding with OneAgent deployment", "OneAgent version", result.AgentVersion)
 
-	agentFolder := GetAgentFolder(targetBaseFolder, result.AgentVersion)
-	if result.Status == NotDeployed {
-		// the versioned agent folder does not exist, copy the agent
-		err = copyAgent(logger, sourceBaseFolder, agentFolder, workBaseFolder, technology)
-		if err != nil {
-			return false, fmt.Errorf("failed to deploy OneAgent in the target directory: %w", err)
-		}
-	}
+	o := newOptions(opts)
+	start := time.Now()
+	previousActive, _ := os.Readlink(filepath.Join(targetBaseFolder, ActiveLinkPath))
+
+	err = deploy(ctx, logger, fileLock, result, configured, sourceBaseFolder, targetBaseFolder, workBaseFolder, technology, o)
+
+	recordDeployment(ctx, logger, fileLock, targetBaseFolder, journal.Record{
+		Timestamp:      start.UTC(),
+		Instance:       o.getInstanceID(),
+		Version:        result.AgentVersion,
+		Technologies:   getSelection(technology).Technologies,
+		Duration:       time.Since(start).String(),
+		PreviousActive: previousActive,
+	}, err)
 
-	// create or update the `active` symlink to point to the newly deployed versioned agent folder
-	err = CreateActiveSymlinkAtomically(logger, workBaseFolder, agentFolder)
 	if err != nil {
-		return false, fmt.Errorf("failed to create `active` symlink in the target directory: %w", err)
+		return false, err
 	}
 
 	logger.Info("OneAgent has been successfully deployed", "OneAgent version", result.AgentVersion)
@@ -86,11 +105,68 @@ func DeployOneAgent(logger logr.Logger, sourceBaseFolder, targetBaseFolder, work
 	return true, nil
 }
 
+// deploy performs the steps of the deployment which are still missing according to the given deployment status.
+func deploy(ctx context.Context, logger logr.Logger, fileLock *lock.FileLock, result AgentDeploymentInfo, configured bool, sourceBaseFolder, targetBaseFolder, workBaseFolder, technology string, o options) error {
+	agentFolder := result.AgentFolder
+	if result.Status == NotDeployed || result.Status == Corrupt {
+		// there is no complete versioned agent folder, copy the agent into a fresh one,
+		// a corrupt folder is left in place, as it may still be in use by the applications
+		agentFolder = newAgentFolder(targetBaseFolder, result.AgentVersion, getSelection(technology))
+
+		err := copyAgent(ctx, logger, sourceBaseFolder, agentFolder, workBaseFolder, technology, fileLock.Validate)
+		if err != nil {
+			return fmt.Errorf("failed to deploy OneAgent in the target directory: %w", err)
+		}
+	}
+
+	if !configured {
+		if err := configureAgent(ctx, logger, fileLock, agentFolder, result.AgentVersion, o.configurator); err != nil {
+			return err
+		}
+	}
+
+	if result.Status != Deployed {
+		if err := switchActiveAgent(logger, fileLock, workBaseFolder, agentFolder); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// configureAgent renders the OneAgent configuration before the agent is made active, so it is complete once it is used.
+func configureAgent(ctx context.Context, logger logr.Logger, fileLock *lock.FileLock, agentFolder, agentVersion string, configurator Configurator) error {
+	if err := fileLock.Validate(); err != nil {
+		return fmt.Errorf("deployment lock lost before configuring OneAgent: %w", err)
+	}
+
+	if err := configurator.Configure(ctx, logger, agentFolder, agentVersion); err != nil {
+		return fmt.Errorf("failed to configure OneAgent: %w", err)
+	}
+
+	return nil
+}
+
+// switchActiveAgent creates or updates the `active` symlink to point to the newly deployed versioned agent folder.
+func switchActiveAgent(logger logr.Logger, fileLock *lock.FileLock, workBaseFolder, agentFolder string) error {
+	// another instance may have taken over the lock (e.g., this instance was considered stale), leave the switch to it
+	if err := fileLock.Validate(); err != nil {
+		return fmt.Errorf("deployment lock lost before updating the `active` symlink: %w", err)
+	}
+
+	if err := CreateActiveSymlinkAtomically(logger, workBaseFolder, agentFolder); err != nil {
+		return fmt.Errorf("failed to create `active` symlink in the target directory: %w", err)
+	}
+
+	return nil
+}
+
 // copyAgent atomically copies OneAgent from the source to the destination.
 // Creates a temporary folder, copies code modules from the source to the temporary folder,
-// sets up the current symlink and then atomically moves the temporary folder to the versioned OneAgent folder.
+// sets up the current symlink, writes the completion marker and then atomically moves the temporary folder to the versioned OneAgent folder.
 // Temporary and versioned OneAgent folders must be on the same disk for the atomic move (i.e. renaming).
-func copyAgent(log logr.Logger, sourceBaseFolder, versionedAgentFolder, workBaseFolder string, technology string) error {
+// The validateLock func is called right before the atomic move, an error aborts the move.
+func copyAgent(ctx context.Context, log logr.Logger, sourceBaseFolder, versionedAgentFolder, workBaseFolder string, technology string, validateLock func() error) error {
 	if err := os.MkdirAll(workBaseFolder, dirPerm755); err != nil {
 		return fmt.Errorf("failed to create the work base folder: %w", err)
 	}
@@ -99,12 +175,14 @@ func copyAgent(log logr.Logger, sourceBaseFolder, versionedAgentFolder, workBase
 		return fmt.Errorf("failed to create the target folder: %w", err)
 	}
 
+	selection := getSelection(technology)
+
 	copyFunc := move.SimpleCopy
-	if technology != "" && strings.TrimSpace(technology) != allTechValue {
-		copyFunc = move.CopyByTechnologyWrapper(technology)
+	if selection.Technologies != "" {
+		copyFunc = move.CopyByTechnologyWrapper(selection.Technologies)
 	}
 
-	workFolder, err := os.MkdirTemp(workBaseFolder, "copy-work-*")
+	workFolder, err := os.MkdirTemp(workBaseFolder, copyWorkFolderPrefix+"*")
 	if err != nil {
 		return fmt.Errorf("failed to create the temporary copy work folder: %w", err)
 	}
@@ -116,9 +194,43 @@ func copyAgent(log logr.Logger, sourceBaseFolder, versionedAgentFolder, workBase
 	}()
 
 	copyFunc = move.CreateCurrentSymlinkOnCopy(copyFunc)
+	copyFunc = writeCompletionMarkerOnCopy(copyFunc, selection)
+	copyFunc = validateLockOnCopy(copyFunc, validateLock)
 	copyFunc = move.Atomic(workFolder, copyFunc)
 
-	return copyFunc(log, sourceBaseFolder, versionedAgentFolder)
+	return copyFunc(ctx, log, sourceBaseFolder, versionedAgentFolder)
+}
+
+// validateLockOnCopy wraps the given copy function to validate the deployment lock right after the copy operation,
+// so the atomic move to the versioned OneAgent folder is only committed while the lock is still held.
+func validateLockOnCopy(copyFunc move.CopyFunc, validateLock func() error) move.CopyFunc {
+	return func(ctx context.Context, log logr.Logger, from, to string) error {
+		if err := copyFunc(ctx, log, from, to); err != nil {
+			return err
+		}
+
+		if err := validateLock(); err != nil {
+			return fmt.Errorf("deployment lock lost before moving OneAgent to the target directory: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// writeCompletionMarkerOnCopy wraps the given copy function to write the completion marker once the copy operation is done,
+// so an interrupted copy or a later modification of the versioned OneAgent folder can be detected.
+func writeCompletionMarkerOnCopy(copyFunc move.CopyFunc, selection marker.Selection) move.CopyFunc {
+	return func(ctx context.Context, log logr.Logger, from, to string) error {
+		if err := copyFunc(ctx, log, from, to); err != nil {
+			return err
+		}
+
+		if err := marker.Write(to, selection); err != nil {
+			return fmt.Errorf("failed to write the completion marker: %w", err)
+		}
+
+		return nil
+	}
 }
 
 func getPathToDeploymentLockFile(workBaseFolder string) string {
@@ -1,6 +1,7 @@
 package deployment
 
 import (
+	"context"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/lock"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/move"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
@@ -22,6 +24,10 @@ const (
 	dirPerm700 fs.FileMode = 0o700
 )
 
+func noLockValidation() error {
+	return nil
+}
+
 func TestCopyAgent(t *testing.T) {
 	t.Run("Successful copy from Source to Target", func(t *testing.T) {
 		logger, _ := tests.NewTestLogger()
@@ -34,10 +40,10 @@ func TestCopyAgent(t *testing.T) {
 		workBaseDir := t.TempDir()
 		targetBaseDir := t.TempDir()
 		agentFolder := GetAgentFolder(targetBaseDir, agentVersion)
-		err := copyAgent(logger, sourceBaseDir, agentFolder, workBaseDir, allTechValue)
+		err := copyAgent(t.Context(), logger, sourceBaseDir, agentFolder, workBaseDir, allTechValue, noLockValidation)
 		require.NoError(t, err)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		// the `active` symlink must be missing because it is created after the copy operation
 		require.Equal(t, LinkMissing, result.Status)
 		require.Equal(t, agentVersion, result.AgentVersion)
@@ -67,12 +73,38 @@ func TestCopyAgent(t *testing.T) {
 
 		workBaseDir := t.TempDir()
 		agentFolder := GetAgentFolder(targetBaseDir, agentVersion)
-		err = copyAgent(logger, sourceBaseDir, agentFolder, workBaseDir, allTechValue)
+		err = copyAgent(t.Context(), logger, sourceBaseDir, agentFolder, workBaseDir, allTechValue, noLockValidation)
 		require.ErrorIs(t, err, syscall.EACCES)
 
 		expectedLog := `failed to create the target folder: mkdir .+: permission denied`
 		require.Regexp(t, expectedLog, err.Error())
 	})
+
+	t.Run("Copy is not committed if the deployment lock was lost", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		workBaseDir := t.TempDir()
+		targetBaseDir := t.TempDir()
+		agentFolder := GetAgentFolder(targetBaseDir, agentVersion)
+		err := copyAgent(t.Context(), logger, sourceBaseDir, agentFolder, workBaseDir, allTechValue, func() error {
+			return lock.ErrLockLost
+		})
+		require.ErrorIs(t, err, lock.ErrLockLost)
+
+		// the versioned agent folder must not be created
+		_, err = os.Stat(agentFolder)
+		require.True(t, os.IsNotExist(err))
+
+		// the work folder must be cleaned up
+		entries, err := os.ReadDir(workBaseDir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
 }
 
 func TestDeployOneAgent(t *testing.T) {
@@ -85,16 +117,16 @@ func TestDeployOneAgent(t *testing.T) {
 		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
 
 		targetBaseDir := t.TempDir()
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.Equal(t, NotDeployed, result.Status)
 
 		workBaseDir := t.TempDir()
-		deployed, err := DeployOneAgent(logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
 		require.NoError(t, err)
 		require.True(t, deployed)
 
 		// verify that OneAgent is deployed
-		result = CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result = CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.NoError(t, result.Error)
 		require.Equal(t, Deployed, result.Status)
 		require.Equal(t, agentVersion, result.AgentVersion)
@@ -118,16 +150,16 @@ func TestDeployOneAgent(t *testing.T) {
 		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, "")
 
 		// verify the current status is LinkMissing
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.Equal(t, LinkMissing, result.Status)
 
 		workBaseDir := t.TempDir()
-		deployed, err := DeployOneAgent(logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
 		require.NoError(t, err)
 		require.True(t, deployed)
 
 		// verify that OneAgent is deployed
-		result = CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result = CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.NoError(t, result.Error)
 		require.Equal(t, Deployed, result.Status)
 		require.Equal(t, agentVersion, result.AgentVersion)
@@ -138,6 +170,69 @@ func TestDeployOneAgent(t *testing.T) {
 		assert.True(t, os.IsNotExist(err), "lock file should be removed after the deployment")
 	})
 
+	t.Run("Redeploys OneAgent into a fresh folder when status is Corrupt", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		// setup target directory with an agent folder of an interrupted copy
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
+		corruptFolder := GetAgentFolder(targetBaseDir, agentVersion)
+		require.NoError(t, os.Remove(filepath.Join(corruptFolder, marker.FileName)))
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+		require.Equal(t, Corrupt, result.Status)
+
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, t.TempDir(), allTechValue)
+		require.NoError(t, err)
+		require.True(t, deployed)
+
+		result = CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+		require.NoError(t, result.Error)
+		require.Equal(t, Deployed, result.Status)
+		require.NotEqual(t, corruptFolder, result.AgentFolder)
+		require.Regexp(t, agentVersion+`-\d+$`, result.AgentFolder)
+
+		// the corrupt folder is left in place, it may still be in use
+		assert.DirExists(t, corruptFolder)
+	})
+
+	t.Run("Deploys OneAgent into its own folder when the technologies change", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+		setupManifest(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		workBaseDir := t.TempDir()
+
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, "java")
+		require.NoError(t, err)
+		require.True(t, deployed)
+
+		javaResult := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, "java")
+		require.Equal(t, Deployed, javaResult.Status)
+		require.Equal(t, NotDeployed, CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, "java,nodejs").Status)
+
+		deployed, err = DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, "java,nodejs")
+		require.NoError(t, err)
+		require.True(t, deployed)
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, "java,nodejs")
+		require.Equal(t, Deployed, result.Status)
+		require.Equal(t, GetAgentFolder(targetBaseDir, getAgentFolderName(agentVersion, getSelection("java,nodejs"))), result.AgentFolder)
+		require.NotEqual(t, javaResult.AgentFolder, result.AgentFolder)
+		assert.FileExists(t, filepath.Join(result.AgentFolder, "agent", "bin", agentVersion, "nodejs.so"))
+		assert.NoFileExists(t, filepath.Join(javaResult.AgentFolder, "agent", "bin", agentVersion, "nodejs.so"))
+	})
+
 	t.Run("Skips deployment when lock already held by another instance", func(t *testing.T) {
 		logger, logsObserver := tests.NewTestLogger()
 
@@ -160,7 +255,7 @@ func TestDeployOneAgent(t *testing.T) {
 		}()
 
 		targetBaseDir := t.TempDir()
-		deployed, err := DeployOneAgent(logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
 		require.NoError(t, err)
 		require.False(t, deployed)
 
@@ -168,7 +263,7 @@ func TestDeployOneAgent(t *testing.T) {
 		tests.RequireLogMessage(t, logsObserver, "Another instance holds the deployment lock, skipping deployment")
 
 		// verify deployment was NOT performed
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.Equal(t, NotDeployed, result.Status)
 	})
 
@@ -184,11 +279,11 @@ func TestDeployOneAgent(t *testing.T) {
 		// setup target directory as already deployed
 		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.Equal(t, Deployed, result.Status)
 
 		workBaseDir := t.TempDir()
-		deployed, err := DeployOneAgent(logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
 		require.NoError(t, err)
 		require.False(t, deployed)
 
@@ -210,7 +305,7 @@ func TestDeployOneAgent(t *testing.T) {
 		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
 
 		targetBaseDir := t.TempDir()
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.Equal(t, NotDeployed, result.Status)
 
 		// create a parent directory and set its permission to read-only which cause failure in creating work base folder
@@ -223,7 +318,7 @@ func TestDeployOneAgent(t *testing.T) {
 		}()
 
 		workBaseDir := filepath.Join(workBaseParentDir, "baseDir")
-		deployed, err := DeployOneAgent(logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
 		require.Error(t, err)
 		require.False(t, deployed)
 		require.Contains(t, err.Error(), "error creating work base folder")
@@ -260,7 +355,7 @@ func TestDeployOneAgent(t *testing.T) {
 
 				<-startBarrier
 
-				deployed, err := DeployOneAgent(logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+				deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
 				if err != nil {
 					atomic.AddInt32(&numErrors, 1)
 
@@ -281,7 +376,7 @@ func TestDeployOneAgent(t *testing.T) {
 		assert.Equal(t, int32(0), numErrors)
 
 		// verify that OneAgent is deployed
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.Equal(t, Deployed, result.Status)
 
 		// verify that only one deployment took place
@@ -316,20 +411,51 @@ func TestDeployOneAgent(t *testing.T) {
 
 		// the deployment should remove the stale lock file and proceed with the deployment
 		targetBaseDir := t.TempDir()
-		deployed, err := DeployOneAgent(logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
 		require.NoError(t, err)
 		require.True(t, deployed)
 
 		// verify that the stale lock file was detected and removed
-		tests.RequireLogMessage(t, logsObserver, "Detected stale lock file, removing it")
+		tests.RequireLogMessage(t, logsObserver, "Detected stale lock file, taking it over")
 
 		// verify that OneAgent is deployed
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.NoError(t, result.Error)
 		require.Equal(t, Deployed, result.Status)
 		require.Equal(t, agentVersion, result.AgentVersion)
 	})
 
+	t.Run("Cancelled deployment cleans up the work folder and releases the lock", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		targetBaseDir := t.TempDir()
+		workBaseDir := t.TempDir()
+		deployed, err := DeployOneAgent(ctx, logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue)
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, deployed)
+
+		// verify that OneAgent is not deployed
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+		require.Equal(t, NotDeployed, result.Status)
+
+		// verify that neither the lock file nor a copy work folder is left behind
+		entries, err := os.ReadDir(workBaseDir)
+		require.NoError(t, err)
+
+		for _, entry := range entries {
+			assert.NotEqual(t, deploymentLockFile, entry.Name())
+			assert.NotContains(t, entry.Name(), "copy-work-")
+		}
+	})
+
 	t.Run("Second deployment upgrades OneAgent version", func(t *testing.T) {
 		const (
 			agentVersion1 = "1.325.22.20251002-101422"
@@ -363,16 +489,16 @@ func deployAgentVersions(t *testing.T, agentVersion1 string, agentVersion2 strin
 	workBaseDir := t.TempDir()
 
 	// check that OneAgent v1 is not deployed
-	result := CheckAgentDeploymentStatus(sourceAgentV1BaseDir, targetBaseDir)
+	result := CheckAgentDeploymentStatus(sourceAgentV1BaseDir, targetBaseDir, allTechValue)
 	require.Equal(t, NotDeployed, result.Status)
 
 	// deploy OneAgent v1
-	deployed, err := DeployOneAgent(logger, sourceAgentV1BaseDir, targetBaseDir, workBaseDir, allTechValue)
+	deployed, err := DeployOneAgent(t.Context(), logger, sourceAgentV1BaseDir, targetBaseDir, workBaseDir, allTechValue)
 	require.NoError(t, err)
 	require.True(t, deployed)
 
 	// verify that OneAgent v1 is deployed
-	result = CheckAgentDeploymentStatus(sourceAgentV1BaseDir, targetBaseDir)
+	result = CheckAgentDeploymentStatus(sourceAgentV1BaseDir, targetBaseDir, allTechValue)
 	require.NoError(t, result.Error)
 	require.Equal(t, Deployed, result.Status)
 	require.Equal(t, result.AgentVersion, agentVersion1)
@@ -382,17 +508,44 @@ func deployAgentVersions(t *testing.T, agentVersion1 string, agentVersion2 strin
 	tests.SetupSourceDirectory(t, sourceAgentV2BaseDir, agentVersion2)
 
 	// check that OneAgent v2 is not deployed
-	result = CheckAgentDeploymentStatus(sourceAgentV2BaseDir, targetBaseDir)
+	result = CheckAgentDeploymentStatus(sourceAgentV2BaseDir, targetBaseDir, allTechValue)
 	require.Equal(t, NotDeployed, result.Status)
 
 	// deploy OneAgent v2
-	deployed, err = DeployOneAgent(logger, sourceAgentV2BaseDir, targetBaseDir, workBaseDir, allTechValue)
+	deployed, err = DeployOneAgent(t.Context(), logger, sourceAgentV2BaseDir, targetBaseDir, workBaseDir, allTechValue)
 	require.NoError(t, err)
 	require.True(t, deployed)
 
 	// verify that OneAgent v2 is deployed
-	result = CheckAgentDeploymentStatus(sourceAgentV2BaseDir, targetBaseDir)
+	result = CheckAgentDeploymentStatus(sourceAgentV2BaseDir, targetBaseDir, allTechValue)
 	require.NoError(t, result.Error)
 	require.Equal(t, Deployed, result.Status)
 	require.Equal(t, result.AgentVersion, agentVersion2)
 }
+
+// setupManifest creates a manifest.json with the java and nodejs technologies in the source directory.
+func setupManifest(t *testing.T, sourceBaseDir, agentVersion string) {
+	t.Helper()
+
+	manifestContent := `{
+		"version": "1.0",
+		"technologies": {
+			"java": {
+				"x86": [
+					{"path": "agent/installer.version", "version": "1.0", "md5": "abc123"},
+					{"path": "agent/bin/` + agentVersion + `/java.so", "version": "1.0", "md5": "def456"}
+				]
+			},
+			"nodejs": {
+				"x86": [
+					{"path": "agent/installer.version", "version": "1.0", "md5": "abc123"},
+					{"path": "agent/bin/` + agentVersion + `/nodejs.so", "version": "1.0", "md5": "ghi789"}
+				]
+			}
+		}
+	}`
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceBaseDir, "manifest.json"), []byte(manifestContent), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceBaseDir, "agent", "bin", agentVersion, "java.so"), []byte("java"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceBaseDir, "agent", "bin", agentVersion, "nodejs.so"), []byte("nodejs"), 0o600))
+}
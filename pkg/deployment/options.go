@@ -0,0 +1,70 @@
+package deployment
+
+import (
+	"context"
+	"os"
+
+	"github.com/go-logr/logr"
+)
+
+// Configurator renders the OneAgent configuration of an agent version into the shared target.
+// It is only called while the deployment lock is held, so the configuration is rendered once per agent version.
+type Configurator interface {
+	// IsConfigured returns true if the configuration of the given agent version was already rendered.
+	IsConfigured(agentVersion string) (bool, error)
+	// Configure renders the configuration of the given agent version, which is deployed in the given agent folder.
+	Configure(ctx context.Context, logger logr.Logger, agentFolder, agentVersion string) error
+}
+
+// Option customizes the OneAgent deployment.
+type Option func(*options)
+
+type options struct {
+	configurator Configurator
+	instanceID   string
+}
+
+// WithConfigurator renders the OneAgent configuration using the given Configurator as part of the deployment.
+func WithConfigurator(configurator Configurator) Option {
+	return func(o *options) {
+		o.configurator = configurator
+	}
+}
+
+// WithInstanceID sets the identity of this instance, which is recorded in the deployment history.
+// Defaults to the hostname.
+func WithInstanceID(instanceID string) Option {
+	return func(o *options) {
+		o.instanceID = instanceID
+	}
+}
+
+func newOptions(opts []Option) options {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// IsConfigured returns true if no Configurator is set or the configuration of the given agent version was already rendered.
+func IsConfigured(agentVersion string, opts ...Option) (bool, error) {
+	o := newOptions(opts)
+	if o.configurator == nil {
+		return true, nil
+	}
+
+	return o.configurator.IsConfigured(agentVersion)
+}
+
+func (o options) getInstanceID() string {
+	if o.instanceID != "" {
+		return o.instanceID
+	}
+
+	hostname, _ := os.Hostname()
+
+	return hostname
+}
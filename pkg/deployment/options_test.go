@@ -0,0 +1,103 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConfigurator struct {
+	err        error
+	configured map[string]bool
+	calls      int
+}
+
+func (c *fakeConfigurator) IsConfigured(agentVersion string) (bool, error) {
+	return c.configured[agentVersion], nil
+}
+
+func (c *fakeConfigurator) Configure(_ context.Context, _ logr.Logger, _, agentVersion string) error {
+	c.calls++
+
+	if c.err != nil {
+		return c.err
+	}
+
+	c.configured[agentVersion] = true
+
+	return nil
+}
+
+func TestDeployOneAgentWithConfigurator(t *testing.T) {
+	const agentVersion = "1.327.30.20251107-111521"
+
+	t.Run("configuration is rendered once together with the deployment", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		workBaseDir := t.TempDir()
+		configurator := &fakeConfigurator{configured: map[string]bool{}}
+
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue, WithConfigurator(configurator))
+		require.NoError(t, err)
+		require.True(t, deployed)
+		assert.Equal(t, 1, configurator.calls)
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+		require.Equal(t, Deployed, result.Status)
+
+		deployed, err = DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, workBaseDir, allTechValue, WithConfigurator(configurator))
+		require.NoError(t, err)
+		require.False(t, deployed)
+		assert.Equal(t, 1, configurator.calls)
+	})
+
+	t.Run("missing configuration of a deployed agent is rendered", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
+
+		configurator := &fakeConfigurator{configured: map[string]bool{}}
+
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, t.TempDir(), allTechValue, WithConfigurator(configurator))
+		require.NoError(t, err)
+		require.True(t, deployed)
+		assert.Equal(t, 1, configurator.calls)
+		assert.True(t, configurator.configured[agentVersion])
+	})
+
+	t.Run("failed configuration leaves the agent inactive", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		configurator := &fakeConfigurator{configured: map[string]bool{}, err: errors.New("some error")}
+
+		deployed, err := DeployOneAgent(t.Context(), logger, sourceBaseDir, targetBaseDir, t.TempDir(), allTechValue, WithConfigurator(configurator))
+		require.ErrorContains(t, err, "failed to configure OneAgent: some error")
+		require.False(t, deployed)
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+		require.Equal(t, LinkMissing, result.Status)
+	})
+
+	t.Run("IsConfigured without configurator", func(t *testing.T) {
+		configured, err := IsConfigured(agentVersion)
+		require.NoError(t, err)
+		assert.True(t, configured)
+	})
+}
@@ -0,0 +1,56 @@
+package deployment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
+)
+
+// selectionSuffixLength is the number of hex digits of the selection hash used as the suffix of the versioned OneAgent folder.
+const selectionSuffixLength = 8
+
+// getSelection returns the selection to deploy for the given comma-separated list of technologies on this host.
+// The technologies are normalized, so the order, duplicates and whitespace don't make a difference.
+func getSelection(technology string) marker.Selection {
+	return marker.Selection{
+		Technologies: normalizeTechnologies(technology),
+		Arch:         runtime.GOARCH,
+	}
+}
+
+// normalizeTechnologies returns the sorted, comma-separated list of the given technologies, empty for all technologies.
+func normalizeTechnologies(technology string) string {
+	technology = strings.TrimSpace(technology)
+	if technology == "" || technology == allTechValue {
+		return ""
+	}
+
+	var technologies []string
+
+	for _, tech := range strings.Split(technology, ",") {
+		if tech = strings.TrimSpace(tech); tech != "" {
+			technologies = append(technologies, tech)
+		}
+	}
+
+	slices.Sort(technologies)
+
+	return strings.Join(slices.Compact(technologies), ",")
+}
+
+// getAgentFolderName returns the name of the versioned OneAgent folder for the selection.
+// It is <version> if all technologies are deployed, otherwise <version>-<hash of the selection>,
+// so deployments of different technologies of the same version don't share a folder.
+func getAgentFolderName(agentVersion string, selection marker.Selection) string {
+	if selection.Technologies == "" {
+		return agentVersion
+	}
+
+	hash := sha256.Sum256([]byte(selection.Technologies + "\x00" + selection.Arch))
+
+	return agentVersion + agentFolderSuffixSeparator + hex.EncodeToString(hash[:])[:selectionSuffixLength]
+}
@@ -0,0 +1,40 @@
+package deployment
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSelection(t *testing.T) {
+	t.Run("all technologies", func(t *testing.T) {
+		assert.Equal(t, marker.Selection{Arch: runtime.GOARCH}, getSelection(""))
+		assert.Equal(t, marker.Selection{Arch: runtime.GOARCH}, getSelection(" all "))
+	})
+
+	t.Run("technologies are normalized", func(t *testing.T) {
+		assert.Equal(t, "java,nodejs", getSelection("nodejs, java,,java").Technologies)
+		assert.Equal(t, getSelection("java,nodejs"), getSelection("nodejs,java"))
+	})
+}
+
+func TestGetAgentFolderName(t *testing.T) {
+	const agentVersion = "1.327.30.20251107-111521"
+
+	t.Run("all technologies are deployed into the version folder", func(t *testing.T) {
+		assert.Equal(t, agentVersion, getAgentFolderName(agentVersion, marker.Selection{Arch: "amd64"}))
+	})
+
+	t.Run("other selections are deployed into a folder suffixed with their hash", func(t *testing.T) {
+		java := getAgentFolderName(agentVersion, marker.Selection{Technologies: "java", Arch: "amd64"})
+		javaNodeJS := getAgentFolderName(agentVersion, marker.Selection{Technologies: "java,nodejs", Arch: "amd64"})
+		javaArm := getAgentFolderName(agentVersion, marker.Selection{Technologies: "java", Arch: "arm64"})
+
+		assert.Regexp(t, `^`+agentVersion+`-[0-9a-f]{8}$`, java)
+		assert.NotEqual(t, java, javaNodeJS)
+		assert.NotEqual(t, java, javaArm)
+		assert.Equal(t, java, getAgentFolderName(agentVersion, marker.Selection{Technologies: "java", Arch: "amd64"}))
+	})
+}
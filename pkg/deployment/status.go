@@ -1,14 +1,23 @@
 package deployment
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
 )
 
 const (
 	InstallerVersionFilePath = "agent/installer.version"
 	ActiveLinkPath           = "oneagent/active"
+
+	agentFolderSuffixSeparator = "-"
 )
 
 type Status int
@@ -18,12 +27,15 @@ const (
 	LinkMissing               // The versioned OneAgent folder exists, but the `active` symlink is missing or points elsewhere
 	Deployed                  // The versioned OneAgent folder exists and the `active` symlink points to the deployed OneAgent folder
 	Unknown                   // An error occurred during the deployment status check
+	Corrupt                   // The versioned OneAgent folder exists, but its completion marker is missing or does not match its content
 )
 
 type AgentDeploymentInfo struct {
 	Status       Status
 	AgentVersion string
 	Error        error
+	// AgentFolder is the versioned OneAgent folder the status refers to, empty if the status is NotDeployed or Unknown.
+	AgentFolder string
 }
 
 func NewAgentDeploymentInfo(status Status, version string, err error) AgentDeploymentInfo {
@@ -38,59 +50,153 @@ func (ds Status) String() string {
 		return "Deployment is not complete"
 	case Deployed:
 		return "Deployed"
+	case Corrupt:
+		return "Corrupt"
 	default:
 		return "Unknown"
 	}
 }
 
-func CheckAgentDeploymentStatus(sourceBaseDir string, targetBaseDir string) AgentDeploymentInfo {
+// CheckAgentDeploymentStatus checks whether the OneAgent version of the source is deployed in the target directory,
+// with the given comma-separated list of technologies (all technologies if empty) for the architecture of this host.
+// A versioned OneAgent folder is only considered deployed if its completion marker matches its content,
+// otherwise the status is Corrupt, and the agent has to be deployed again into a fresh folder.
+// Folders of the same version with other technologies or for another architecture are not considered at all.
+// Besides <version>, the versioned OneAgent folder can also be named <version>-<suffix> (see newAgentFolder).
+func CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, technology string) AgentDeploymentInfo {
+	selection := getSelection(technology)
+
 	agentVersion, err := getAgentVersion(sourceBaseDir)
 	if err != nil {
 		return NewAgentDeploymentInfo(Unknown, "", fmt.Errorf("failed to determine OneAgent version to deploy: %w", err))
 	}
 
-	// check whether the agent directory exists
+	// check whether the agent directory is accessible
 	agentDirPath := GetAgentFolder(targetBaseDir, agentVersion)
 
 	info, err := os.Stat(agentDirPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return NewAgentDeploymentInfo(NotDeployed, agentVersion, nil)
-		}
-
+	if err != nil && !os.IsNotExist(err) {
 		return NewAgentDeploymentInfo(Unknown, agentVersion, fmt.Errorf("cannot obtain OneAgent directory info: %w", err))
 	}
 
-	if !info.IsDir() {
+	if err == nil && !info.IsDir() {
 		return NewAgentDeploymentInfo(Unknown, agentVersion, fmt.Errorf("OneAgent deployment target is not a directory: %s", agentDirPath))
 	}
 
-	// check whether the oneagent active symlink exists
+	activeLinkTarget, err := readActiveLink(targetBaseDir)
+	if err != nil {
+		return NewAgentDeploymentInfo(Unknown, agentVersion, err)
+	}
+
+	// check whether the oneagent active symlink points to a folder of the agent version
+	if isAgentFolderOf(activeLinkTarget, agentVersion) {
+		activeFolder := GetAgentFolder(targetBaseDir, activeLinkTarget)
+
+		// if the active folder is corrupt, there still may be a complete one to switch to
+		result := checkAgentFolder(activeFolder, agentVersion, selection, Deployed)
+		if result.Status == Deployed || result.Status == Unknown {
+			return result
+		}
+	}
+
+	return findAgentFolder(targetBaseDir, agentVersion, selection)
+}
+
+// readActiveLink returns the target of the `active` symlink, or an empty string if it does not exist.
+func readActiveLink(targetBaseDir string) (string, error) {
 	activeLink := filepath.Join(targetBaseDir, ActiveLinkPath)
 
-	info, err = os.Lstat(activeLink)
+	info, err := os.Lstat(activeLink)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewAgentDeploymentInfo(LinkMissing, agentVersion, nil)
+			return "", nil
 		}
 
-		return NewAgentDeploymentInfo(Unknown, agentVersion, fmt.Errorf("cannot obtain OneAgent `active` symlink info: %w", err))
+		return "", fmt.Errorf("cannot obtain OneAgent `active` symlink info: %w", err)
 	}
 
 	if info.Mode()&os.ModeSymlink == 0 {
-		return NewAgentDeploymentInfo(Unknown, agentVersion, fmt.Errorf("OneAgent `active` is not a symlink: %s", info.Mode().String()))
+		return "", fmt.Errorf("OneAgent `active` is not a symlink: %s", info.Mode().String())
 	}
 
 	activeLinkTarget, err := os.Readlink(activeLink)
 	if err != nil {
-		return NewAgentDeploymentInfo(Unknown, agentVersion, fmt.Errorf("cannot read OneAgent `active` symlink: %w", err))
+		return "", fmt.Errorf("cannot read OneAgent `active` symlink: %w", err)
 	}
 
-	if activeLinkTarget != agentVersion {
-		return NewAgentDeploymentInfo(LinkMissing, agentVersion, nil)
+	return activeLinkTarget, nil
+}
+
+// findAgentFolder looks for a complete versioned OneAgent folder of the agent version and selection which is not active.
+// Returns LinkMissing if there is a complete one, Corrupt if there are only incomplete ones, NotDeployed if there is none.
+func findAgentFolder(targetBaseDir, agentVersion string, selection marker.Selection) AgentDeploymentInfo {
+	agentsFolder := filepath.Dir(GetAgentFolder(targetBaseDir, agentVersion))
+
+	entries, err := os.ReadDir(agentsFolder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewAgentDeploymentInfo(NotDeployed, agentVersion, nil)
+		}
+
+		return NewAgentDeploymentInfo(Unknown, agentVersion, fmt.Errorf("cannot list the OneAgent directories: %w", err))
 	}
 
-	return NewAgentDeploymentInfo(Deployed, agentVersion, nil)
+	result := NewAgentDeploymentInfo(NotDeployed, agentVersion, nil)
+
+	// the entries are sorted by name, prefer the latest complete folder
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].IsDir() || !isAgentFolderOf(entries[i].Name(), agentVersion) {
+			continue
+		}
+
+		folderResult := checkAgentFolder(filepath.Join(agentsFolder, entries[i].Name()), agentVersion, selection, LinkMissing)
+		switch folderResult.Status { //nolint:exhaustive
+		case LinkMissing, Unknown:
+			return folderResult
+		case Corrupt:
+			if result.Status == NotDeployed {
+				result = folderResult
+			}
+		}
+	}
+
+	return result
+}
+
+// checkAgentFolder returns the given status if the completion marker of the agent folder matches its content and the selection,
+// Corrupt if it does not match its content, NotDeployed if the folder does not exist or is of another selection.
+// The selection of a folder without a valid marker is unknown, so it is considered Corrupt as well,
+// but only if all technologies are requested: it must not count as a deployment of some technologies.
+func checkAgentFolder(agentFolder, agentVersion string, selection marker.Selection, completeStatus Status) AgentDeploymentInfo {
+	state, err := marker.Read(agentFolder)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return NewAgentDeploymentInfo(NotDeployed, agentVersion, nil)
+		}
+
+		return NewAgentDeploymentInfo(Unknown, agentVersion, fmt.Errorf("cannot verify the OneAgent directory: %w", err))
+	}
+
+	result := NewAgentDeploymentInfo(completeStatus, agentVersion, nil)
+
+	switch {
+	case state.Complete && state.Selection == selection:
+	case !state.Complete && state.Selection == marker.Selection{} && selection.Technologies == "":
+		result.Status = Corrupt
+	case !state.Complete && state.Selection == selection:
+		result.Status = Corrupt
+	default:
+		return NewAgentDeploymentInfo(NotDeployed, agentVersion, nil)
+	}
+
+	result.AgentFolder = agentFolder
+
+	return result
+}
+
+// isAgentFolderOf returns true if the name of the versioned OneAgent folder is <version> or <version>-<suffix>.
+func isAgentFolderOf(folderName, agentVersion string) bool {
+	return folderName == agentVersion || strings.HasPrefix(folderName, agentVersion+agentFolderSuffixSeparator)
 }
 
 // GetAgentFolder returns the absolute path to the specified version of the OneAgent directory
@@ -111,3 +217,20 @@ func getAgentVersion(sourceBasePath string) (string, error) {
 
 	return string(version), nil
 }
+
+// newAgentFolder returns a versioned OneAgent folder for the agent version and selection which does not exist yet.
+// It is named according to getAgentFolderName for the first deployment,
+// with an additional -<unix-time> suffix if that already exists (e.g., because it is corrupt),
+// as a folder in use by the applications can't be replaced.
+func newAgentFolder(targetBaseDir, agentVersion string, selection marker.Selection) string {
+	folderName := getAgentFolderName(agentVersion, selection)
+	agentFolder := GetAgentFolder(targetBaseDir, folderName)
+
+	for suffix := time.Now().Unix(); ; suffix++ {
+		if _, err := os.Lstat(agentFolder); os.IsNotExist(err) {
+			return agentFolder
+		}
+
+		agentFolder = GetAgentFolder(targetBaseDir, folderName+agentFolderSuffixSeparator+strconv.FormatInt(suffix, 10))
+	}
+}
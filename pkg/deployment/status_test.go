@@ -6,6 +6,7 @@ import (
 	"syscall"
 	"testing"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
 	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
 	"github.com/stretchr/testify/require"
 )
@@ -18,7 +19,7 @@ func TestDeploymentStatus(t *testing.T) {
 		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
 
 		targetBaseDir := t.TempDir()
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.NoError(t, result.Error)
 		require.Equal(t, NotDeployed, result.Status)
@@ -36,7 +37,7 @@ func TestDeploymentStatus(t *testing.T) {
 		targetBaseDir := t.TempDir()
 		tests.SetupTargetDirectory(t, targetBaseDir, targetAgentVersion, targetAgentVersion)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.NoError(t, result.Error)
 		require.Equal(t, NotDeployed, result.Status)
@@ -51,7 +52,7 @@ func TestDeploymentStatus(t *testing.T) {
 		targetBaseDir := t.TempDir()
 		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, "")
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.NoError(t, result.Error)
 		require.Equal(t, LinkMissing, result.Status)
@@ -69,7 +70,7 @@ func TestDeploymentStatus(t *testing.T) {
 		targetBaseDir := t.TempDir()
 		tests.SetupTargetDirectory(t, targetBaseDir, sourceAgentVersion, targetAgentVersion)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.NoError(t, result.Error)
 		require.Equal(t, LinkMissing, result.Status)
@@ -84,12 +85,135 @@ func TestDeploymentStatus(t *testing.T) {
 		targetBaseDir := t.TempDir()
 		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.NoError(t, result.Error)
 		require.Equal(t, Deployed, result.Status)
 	})
 
+	t.Run("the deployment status is 'Corrupt' (the completion marker is missing)", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
+		require.NoError(t, os.Remove(filepath.Join(GetAgentFolder(targetBaseDir, agentVersion), marker.FileName)))
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+
+		require.NoError(t, result.Error)
+		require.Equal(t, Corrupt, result.Status)
+		require.Equal(t, GetAgentFolder(targetBaseDir, agentVersion), result.AgentFolder)
+	})
+
+	t.Run("the deployment status is 'Corrupt' (the content does not match the completion marker)", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, "")
+		require.NoError(t, os.WriteFile(filepath.Join(GetAgentFolder(targetBaseDir, agentVersion), "partial"), nil, 0o600))
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+
+		require.NoError(t, result.Error)
+		require.Equal(t, Corrupt, result.Status)
+	})
+
+	t.Run("the deployment status is 'Deployed' (the `active` symlink points to a redeployed folder)", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, "")
+		require.NoError(t, os.Remove(filepath.Join(GetAgentFolder(targetBaseDir, agentVersion), marker.FileName)))
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion+"-1700000000", agentVersion+"-1700000000")
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+
+		require.NoError(t, result.Error)
+		require.Equal(t, Deployed, result.Status)
+		require.Equal(t, GetAgentFolder(targetBaseDir, agentVersion+"-1700000000"), result.AgentFolder)
+	})
+
+	t.Run("the deployment status is 'Link Missing' (a redeployed folder is complete)", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
+		require.NoError(t, os.Remove(filepath.Join(GetAgentFolder(targetBaseDir, agentVersion), marker.FileName)))
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion+"-1700000000", "")
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
+
+		require.NoError(t, result.Error)
+		require.Equal(t, LinkMissing, result.Status)
+		require.Equal(t, GetAgentFolder(targetBaseDir, agentVersion+"-1700000000"), result.AgentFolder)
+	})
+
+	t.Run("the deployment status is 'Not Deployed' (the deployed folder contains other technologies)", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, "java")
+
+		require.NoError(t, result.Error)
+		require.Equal(t, NotDeployed, result.Status)
+	})
+
+	t.Run("the deployment status is 'Not Deployed' (the folder without completion marker has an unknown selection)", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, agentVersion)
+		require.NoError(t, os.Remove(filepath.Join(GetAgentFolder(targetBaseDir, agentVersion), marker.FileName)))
+
+		for _, technology := range []string{"java", "java,nodejs"} {
+			result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, technology)
+
+			require.NoError(t, result.Error)
+			require.Equal(t, NotDeployed, result.Status, technology)
+			require.Empty(t, result.AgentFolder, technology)
+		}
+
+		require.Equal(t, Corrupt, CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue).Status)
+	})
+
+	t.Run("the deployment status is 'Deployed' (the selection matches regardless of the order of the technologies)", func(t *testing.T) {
+		const agentVersion = "1.327.30.20251107-111521"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, agentVersion)
+
+		targetBaseDir := t.TempDir()
+		folderName := getAgentFolderName(agentVersion, getSelection("java,nodejs"))
+		tests.SetupTargetDirectory(t, targetBaseDir, folderName, folderName)
+		require.NoError(t, marker.Write(GetAgentFolder(targetBaseDir, folderName), getSelection("java,nodejs")))
+
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, "nodejs, java")
+
+		require.NoError(t, result.Error)
+		require.Equal(t, Deployed, result.Status)
+		require.Equal(t, NotDeployed, CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue).Status)
+	})
+
 	t.Run("the deployment status is 'Unknown' (due to the target folder permission issue)", func(t *testing.T) {
 		const agentVersion = "1.327.30.20251107-111521"
 
@@ -110,7 +234,7 @@ func TestDeploymentStatus(t *testing.T) {
 
 		require.NoError(t, err)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 		require.ErrorIs(t, result.Error, syscall.EACCES)
 		require.Equal(t, Unknown, result.Status)
 
@@ -132,7 +256,7 @@ func TestDeploymentStatus(t *testing.T) {
 		err := os.MkdirAll(activeDirectoryPath, 0755)
 		require.NoError(t, err)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.ErrorContains(t, result.Error, "OneAgent `active` is not a symlink: drwxr-xr-x")
 		require.Equal(t, Unknown, result.Status)
@@ -159,7 +283,7 @@ func TestDeploymentStatus(t *testing.T) {
 			require.NoError(t, file.Close())
 		}()
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.ErrorContains(t, result.Error, "OneAgent deployment target is not a directory")
 		require.Equal(t, Unknown, result.Status)
@@ -179,7 +303,7 @@ func TestDeploymentStatus(t *testing.T) {
 		err := os.Remove(versionFilePath)
 		require.NoError(t, err)
 
-		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir)
+		result := CheckAgentDeploymentStatus(sourceBaseDir, targetBaseDir, allTechValue)
 
 		require.ErrorContains(t, result.Error, "failed to determine OneAgent version to deploy")
 		require.ErrorIs(t, result.Error, syscall.ENOENT)
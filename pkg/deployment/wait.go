@@ -0,0 +1,122 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs/watch"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
+	"github.com/go-logr/logr"
+)
+
+// Waiter waits for changes of a single file, e.g. the `active` symlink in the target directory.
+// It relies on file system events (inotify) when available, so a completed deployment is noticed almost immediately.
+// As file system events are not available (or not reported for changes of other hosts) on many network file systems,
+// it always falls back to polling with the configured interval as well.
+type Waiter struct {
+	logger       logr.Logger
+	watcher      *watch.Watcher
+	dir          string
+	name         string
+	pollInterval time.Duration
+	unsupported  bool
+}
+
+// NewActiveLinkWaiter creates a Waiter for the `active` symlink in the given target base directory.
+// It is the caller's responsibility to close it when no longer needed.
+func NewActiveLinkWaiter(logger logr.Logger, targetBaseDir string, pollInterval time.Duration) *Waiter {
+	return newWaiter(logger, filepath.Join(targetBaseDir, ActiveLinkPath), pollInterval)
+}
+
+// NewSourceVersionWaiter creates a Waiter for the installer.version file in the given source base directory.
+// It is the caller's responsibility to close it when no longer needed.
+func NewSourceVersionWaiter(logger logr.Logger, sourceBaseDir string, pollInterval time.Duration) *Waiter {
+	return newWaiter(logger, filepath.Join(sourceBaseDir, InstallerVersionFilePath), pollInterval)
+}
+
+func newWaiter(logger logr.Logger, path string, pollInterval time.Duration) *Waiter {
+	return &Waiter{
+		logger:       logger,
+		dir:          filepath.Dir(path),
+		name:         filepath.Base(path),
+		pollInterval: pollInterval,
+	}
+}
+
+// Wait blocks until the file may have changed, the poll interval has passed or the context is cancelled.
+// The caller must check the deployment status itself afterward, a return does not guarantee any change.
+func (w *Waiter) Wait(ctx context.Context) error {
+	w.ensureWatcher()
+
+	var events <-chan string
+	if w.watcher != nil {
+		events = w.watcher.Events()
+	}
+
+	timer := time.NewTimer(w.pollInterval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case name, ok := <-events:
+			if !ok {
+				// the watcher stopped unexpectedly, recreate it on the next call
+				w.closeWatcher()
+
+				return nil
+			}
+
+			if name == w.name {
+				log.Debug(w.logger, "Detected a change", "directory", w.dir, "name", w.name)
+
+				return nil
+			}
+		}
+	}
+}
+
+// Close stops watching for file system events.
+func (w *Waiter) Close() {
+	w.closeWatcher()
+}
+
+// ensureWatcher sets up the file system watch if possible.
+// The directory of the file might not exist yet, so setting up the watch is retried on every call.
+func (w *Waiter) ensureWatcher() {
+	if w.watcher != nil || w.unsupported {
+		return
+	}
+
+	watcher, err := watch.New(w.dir)
+	if err != nil {
+		if errors.Is(err, watch.ErrUnsupported) {
+			w.unsupported = true
+		}
+
+		log.Debug(w.logger, "File system events are not available, falling back to polling", "directory", w.dir, "poll interval", w.pollInterval.String(), "reason", err.Error())
+
+		return
+	}
+
+	log.Debug(w.logger, "Watching for changes", "directory", w.dir, "name", w.name)
+
+	w.watcher = watcher
+}
+
+func (w *Waiter) closeWatcher() {
+	if w.watcher == nil {
+		return
+	}
+
+	if err := w.watcher.Close(); err != nil {
+		log.Debug(w.logger, "Failed to stop watching for file system events", "directory", w.dir, "error", err.Error())
+	}
+
+	w.watcher = nil
+}
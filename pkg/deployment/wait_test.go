@@ -0,0 +1,110 @@
+package deployment
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveLinkWaiter(t *testing.T) {
+	t.Run("Wait returns as soon as the `active` symlink is created", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		const agentVersion = "1.327.30.20251107-111521"
+
+		targetBaseDir := t.TempDir()
+		tests.SetupTargetDirectory(t, targetBaseDir, agentVersion, "")
+
+		waiter := NewActiveLinkWaiter(logger, targetBaseDir, time.Minute)
+		defer waiter.Close()
+
+		// set up the watch before the change happens
+		waiter.ensureWatcher()
+		require.NotNil(t, waiter.watcher)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			assert.NoError(t, CreateActiveSymlinkAtomically(logger, t.TempDir(), GetAgentFolder(targetBaseDir, agentVersion)))
+		}()
+
+		start := time.Now()
+		require.NoError(t, waiter.Wait(context.Background()))
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("Wait falls back to polling if the directory does not exist", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		const pollInterval = 100 * time.Millisecond
+
+		waiter := NewActiveLinkWaiter(logger, filepath.Join(t.TempDir(), "missing"), pollInterval)
+		defer waiter.Close()
+
+		start := time.Now()
+		require.NoError(t, waiter.Wait(context.Background()))
+		assert.GreaterOrEqual(t, time.Since(start), pollInterval)
+		assert.Nil(t, waiter.watcher)
+	})
+
+	t.Run("Wait sets up the watch once the directory exists", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		targetBaseDir := t.TempDir()
+
+		waiter := NewActiveLinkWaiter(logger, targetBaseDir, 10*time.Millisecond)
+		defer waiter.Close()
+
+		require.NoError(t, waiter.Wait(context.Background()))
+		require.Nil(t, waiter.watcher)
+
+		require.NoError(t, os.MkdirAll(filepath.Join(targetBaseDir, "oneagent"), dirPerm755))
+
+		require.NoError(t, waiter.Wait(context.Background()))
+		require.NotNil(t, waiter.watcher)
+	})
+
+	t.Run("Wait returns when the context is cancelled", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		waiter := NewActiveLinkWaiter(logger, t.TempDir(), time.Minute)
+		defer waiter.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		require.ErrorIs(t, waiter.Wait(ctx), context.Canceled)
+	})
+}
+
+func TestSourceVersionWaiter(t *testing.T) {
+	t.Run("Wait returns as soon as the installer.version is updated", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		const newAgentVersion = "1.329.10.20251201-101010"
+
+		sourceBaseDir := t.TempDir()
+		tests.SetupSourceDirectory(t, sourceBaseDir, "1.327.30.20251107-111521")
+
+		waiter := NewSourceVersionWaiter(logger, sourceBaseDir, time.Minute)
+		defer waiter.Close()
+
+		// set up the watch before the change happens
+		waiter.ensureWatcher()
+		require.NotNil(t, waiter.watcher)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			assert.NoError(t, os.WriteFile(filepath.Join(sourceBaseDir, InstallerVersionFilePath), []byte(newAgentVersion), 0o600))
+		}()
+
+		start := time.Now()
+		require.NoError(t, waiter.Wait(context.Background()))
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+}
@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/go-logr/logr"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+	StatusPath    = "/status"
+
+	readHeaderTimeout = 5 * time.Second
+	shutdownTimeout   = 5 * time.Second
+)
+
+// StatusFunc returns the current OneAgent deployment status.
+type StatusFunc func() deployment.AgentDeploymentInfo
+
+// Server serves the liveness, readiness and status endpoints of the Bootstrapper in keep-alive mode.
+type Server struct {
+	logger   logr.Logger
+	server   *http.Server
+	listener net.Listener
+	status   StatusFunc
+}
+
+// StatusResponse is the JSON representation of the OneAgent deployment status.
+type StatusResponse struct {
+	Status       string `json:"status"`
+	AgentVersion string `json:"agentVersion,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// NewServer creates a new Server listening on the given address, the readiness and status endpoints use the given StatusFunc.
+func NewServer(logger logr.Logger, addr string, status StatusFunc) *Server {
+	s := &Server{
+		logger: logger,
+		status: status,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, s.handleLiveness)
+	mux.HandleFunc(ReadinessPath, s.handleReadiness)
+	mux.HandleFunc(StatusPath, s.handleStatus)
+
+	s.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	return s
+}
+
+// Start starts listening on the configured address and serves the requests in the background.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on the health address: %w", err)
+	}
+
+	s.listener = listener
+
+	s.logger.Info("Serving health endpoints", "address", listener.Addr().String())
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error(err, "health server stopped unexpectedly")
+		}
+	}()
+
+	return nil
+}
+
+// Addr returns the address the Server is listening on, it is only available after Start.
+func (s *Server) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+
+	return s.listener.Addr().String()
+}
+
+// Shutdown gracefully stops the Server.
+func (s *Server) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Error(err, "failed to shut down the health server")
+	}
+}
+
+func (s *Server) handleLiveness(w http.ResponseWriter, _ *http.Request) {
+	writeText(w, http.StatusOK, "ok")
+}
+
+// handleReadiness only succeeds once OneAgent is deployed, so startup probes can gate the application's traffic.
+func (s *Server) handleReadiness(w http.ResponseWriter, _ *http.Request) {
+	info := s.status()
+	if info.Status != deployment.Deployed {
+		writeText(w, http.StatusServiceUnavailable, info.Status.String())
+
+		return
+	}
+
+	writeText(w, http.StatusOK, "ready")
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	info := s.status()
+
+	response := StatusResponse{
+		Status:       info.Status.String(),
+		AgentVersion: info.AgentVersion,
+	}
+
+	if info.Error != nil {
+		response.Error = info.Error.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.logger.Error(err, "failed to write the status response")
+	}
+}
+
+func writeText(w http.ResponseWriter, code int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write([]byte(text + "\n"))
+}
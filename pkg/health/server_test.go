@@ -0,0 +1,115 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment"
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const agentVersion = "1.327.30.20251107-111521"
+
+func TestServer(t *testing.T) {
+	t.Run("liveness succeeds regardless of the deployment status", func(t *testing.T) {
+		server := startServer(t, deployment.NewAgentDeploymentInfo(deployment.NotDeployed, agentVersion, nil))
+
+		code, _ := get(t, server, LivenessPath)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("readiness fails until OneAgent is deployed", func(t *testing.T) {
+		server := startServer(t, deployment.NewAgentDeploymentInfo(deployment.LinkMissing, agentVersion, nil))
+
+		code, body := get(t, server, ReadinessPath)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, deployment.LinkMissing.String())
+	})
+
+	t.Run("readiness succeeds when OneAgent is deployed", func(t *testing.T) {
+		server := startServer(t, deployment.NewAgentDeploymentInfo(deployment.Deployed, agentVersion, nil))
+
+		code, _ := get(t, server, ReadinessPath)
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	t.Run("readiness fails once the deployment is corrupt", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		var corrupt atomic.Bool
+
+		server := NewServer(logger, "127.0.0.1:0", func() deployment.AgentDeploymentInfo {
+			if corrupt.Load() {
+				return deployment.NewAgentDeploymentInfo(deployment.Corrupt, agentVersion, nil)
+			}
+
+			return deployment.NewAgentDeploymentInfo(deployment.Deployed, agentVersion, nil)
+		})
+		require.NoError(t, server.Start())
+		t.Cleanup(server.Shutdown)
+
+		code, _ := get(t, server, ReadinessPath)
+		require.Equal(t, http.StatusOK, code)
+
+		corrupt.Store(true)
+
+		code, body := get(t, server, ReadinessPath)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Contains(t, body, deployment.Corrupt.String())
+	})
+
+	t.Run("status returns the deployment info as JSON", func(t *testing.T) {
+		server := startServer(t, deployment.NewAgentDeploymentInfo(deployment.Unknown, agentVersion, errors.New("some error")))
+
+		code, body := get(t, server, StatusPath)
+		require.Equal(t, http.StatusOK, code)
+
+		var response StatusResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &response))
+		assert.Equal(t, StatusResponse{Status: "Unknown", AgentVersion: agentVersion, Error: "some error"}, response)
+	})
+
+	t.Run("start fails for an invalid address", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		server := NewServer(logger, "invalid-address", nil)
+		require.Error(t, server.Start())
+	})
+}
+
+func startServer(t *testing.T, info deployment.AgentDeploymentInfo) *Server {
+	t.Helper()
+
+	logger, _ := tests.NewTestLogger()
+
+	server := NewServer(logger, "127.0.0.1:0", func() deployment.AgentDeploymentInfo {
+		return info
+	})
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Shutdown)
+
+	return server
+}
+
+func get(t *testing.T, server *Server, path string) (int, string) {
+	t.Helper()
+
+	request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "http://"+server.Addr()+path, nil)
+	require.NoError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := io.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	return response.StatusCode, string(body)
+}
@@ -0,0 +1,204 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxSize is the default size in bytes after which the journal file is rotated.
+	DefaultMaxSize = 1024 * 1024
+
+	// DefaultMaxBackups is the default number of rotated journal files which are kept.
+	DefaultMaxBackups = 1
+
+	filePerm644 fs.FileMode = 0o644
+)
+
+// Outcomes of a deployment.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeAborted = "aborted"
+)
+
+// Record describes a single deployment.
+type Record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Instance  string    `json:"instance"`
+	Version   string    `json:"version"`
+	// Technologies is empty if all technologies were deployed.
+	Technologies string `json:"technologies,omitempty"`
+	Duration     string `json:"duration"`
+	Outcome      string `json:"outcome"`
+	Error        string `json:"error,omitempty"`
+	// PreviousActive is the target of the `active` symlink before the deployment, empty if it did not exist.
+	PreviousActive string `json:"previousActive,omitempty"`
+}
+
+// Journal is a JSON-lines file, every line is a Record.
+// If appending a Record would exceed the max size, the file is rotated to <path>.1, the older backups are shifted
+// to <path>.2 and so on, the oldest backup is removed.
+//
+// The Journal does not synchronize concurrent writers, the caller must ensure there is only one (e.g., by holding a lock).
+type Journal struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+}
+
+// New creates a new Journal instance with the default max size and backups.
+func New(path string) *Journal {
+	return &Journal{
+		path:       path,
+		maxSize:    DefaultMaxSize,
+		maxBackups: DefaultMaxBackups,
+	}
+}
+
+// WithMaxSize sets the size in bytes after which the journal file is rotated.
+func (j *Journal) WithMaxSize(maxSize int64) *Journal {
+	j.maxSize = maxSize
+
+	return j
+}
+
+// WithMaxBackups sets the number of rotated journal files which are kept.
+func (j *Journal) WithMaxBackups(maxBackups int) *Journal {
+	j.maxBackups = maxBackups
+
+	return j
+}
+
+// Append appends the Record to the journal file, rotating it first if needed.
+func (j *Journal) Append(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the journal record: %w", err)
+	}
+
+	line = append(line, '\n')
+
+	if err := j.rotateIfNeeded(int64(len(line))); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, filePerm644)
+	if err != nil {
+		return fmt.Errorf("failed to open the journal: %w", err)
+	}
+
+	_, err = file.Write(line)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to append to the journal: %w", err)
+	}
+
+	return nil
+}
+
+// Read returns all records of the journal, including the rotated ones, from the oldest to the newest.
+// Lines which can't be parsed (e.g., written partially by a crashed instance) are skipped.
+func (j *Journal) Read() ([]Record, error) {
+	var records []Record
+
+	for backup := j.maxBackups; backup >= 0; backup-- {
+		fileRecords, err := readFile(j.backupPath(backup))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		records = append(records, fileRecords...)
+	}
+
+	return records, nil
+}
+
+func (j *Journal) rotateIfNeeded(lineSize int64) error {
+	info, err := os.Stat(j.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to check the journal size: %w", err)
+	}
+
+	if info.Size()+lineSize <= j.maxSize {
+		return nil
+	}
+
+	if j.maxBackups <= 0 {
+		return removeIfExists(j.path)
+	}
+
+	if err := removeIfExists(j.backupPath(j.maxBackups)); err != nil {
+		return err
+	}
+
+	for backup := j.maxBackups - 1; backup >= 0; backup-- {
+		err := os.Rename(j.backupPath(backup), j.backupPath(backup+1))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate the journal: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// backupPath returns the path of the given backup, 0 is the current journal file.
+func (j *Journal) backupPath(backup int) string {
+	if backup == 0 {
+		return j.path
+	}
+
+	return j.path + "." + strconv.Itoa(backup)
+}
+
+func readFile(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = file.Close() }()
+
+	var records []Record
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			continue
+		}
+
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the journal %s: %w", path, err)
+	}
+
+	return records, nil
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove the journal %s: %w", path, err)
+	}
+
+	return nil
+}
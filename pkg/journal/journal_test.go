@@ -0,0 +1,109 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const journalFile = "history.jsonl"
+
+func newRecord(version string) Record {
+	return Record{
+		Timestamp: time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC),
+		Instance:  "instance-1",
+		Version:   version,
+		Duration:  "1.5s",
+		Outcome:   OutcomeSuccess,
+	}
+}
+
+func TestJournal(t *testing.T) {
+	t.Run("append and read", func(t *testing.T) {
+		journal := New(filepath.Join(t.TempDir(), journalFile))
+
+		records, err := journal.Read()
+		require.NoError(t, err)
+		assert.Empty(t, records)
+
+		require.NoError(t, journal.Append(newRecord("1")))
+		require.NoError(t, journal.Append(newRecord("2")))
+
+		records, err = journal.Read()
+		require.NoError(t, err)
+		assert.Equal(t, []Record{newRecord("1"), newRecord("2")}, records)
+	})
+
+	t.Run("malformed lines are skipped", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), journalFile)
+		journal := New(path)
+
+		require.NoError(t, journal.Append(newRecord("1")))
+
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+		require.NoError(t, err)
+		_, err = file.WriteString(`{"timestamp":"2026-10`)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		records, err := journal.Read()
+		require.NoError(t, err)
+		assert.Equal(t, []Record{newRecord("1")}, records)
+	})
+
+	t.Run("rotation keeps the max backups", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), journalFile)
+
+		lineSize := recordSize(t, newRecord("1"))
+		journal := New(path).WithMaxSize(2 * lineSize).WithMaxBackups(2)
+
+		for i := 1; i <= 7; i++ {
+			require.NoError(t, journal.Append(newRecord(strconv.Itoa(i))))
+		}
+
+		assert.FileExists(t, path+".1")
+		assert.FileExists(t, path+".2")
+		assert.NoFileExists(t, path+".3")
+
+		records, err := journal.Read()
+		require.NoError(t, err)
+
+		var versions []string
+		for _, record := range records {
+			versions = append(versions, record.Version)
+		}
+
+		// 1 and 2 were in the oldest backup, which was removed by the last rotation
+		assert.Equal(t, []string{"3", "4", "5", "6", "7"}, versions)
+	})
+
+	t.Run("no backups ==> journal is truncated", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), journalFile)
+
+		journal := New(path).WithMaxSize(recordSize(t, newRecord("1"))).WithMaxBackups(0)
+
+		require.NoError(t, journal.Append(newRecord("1")))
+		require.NoError(t, journal.Append(newRecord("2")))
+
+		records, err := journal.Read()
+		require.NoError(t, err)
+		assert.Equal(t, []Record{newRecord("2")}, records)
+	})
+}
+
+func recordSize(t *testing.T, record Record) int64 {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), journalFile)
+	require.NoError(t, New(path).Append(record))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	return info.Size()
+}
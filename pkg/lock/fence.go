@@ -0,0 +1,117 @@
+package lock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	fenceFileSuffix = ".fence"
+	ownerIDBytes    = 8
+	lockFileParts   = 2
+)
+
+var errMalformedFenceFile = errors.New("malformed fence file")
+
+// nextToken issues the next fencing token.
+// The last issued token is persisted in a fence file next to the lock file, which is only written by the lock holder.
+// The token of a taken over stale lock is considered as well, in case its holder did not persist its token.
+// A malformed fence file is overwritten, restarting from the token of the taken over stale lock, so it does not block every later acquisition.
+func (l *FileLock) nextToken(previousToken uint64) (uint64, error) {
+	lastToken, err := readFenceFile(l.fencePath())
+
+	switch {
+	case errors.Is(err, errMalformedFenceFile):
+		l.logger.Info("Fence file is malformed, overwriting it", "path", l.fencePath(), "error", err.Error())
+
+		lastToken = 0
+	case err != nil && !os.IsNotExist(err):
+		return 0, err
+	}
+
+	token := max(lastToken, previousToken) + 1
+
+	if err := writeFenceFile(l.fencePath(), token); err != nil {
+		return 0, err
+	}
+
+	return token, nil
+}
+
+func (l *FileLock) fencePath() string {
+	return l.path + fenceFileSuffix
+}
+
+func readFenceFile(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	token, err := strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %s: %w", errMalformedFenceFile, path, err)
+	}
+
+	return token, nil
+}
+
+// writeFenceFile atomically replaces the fence file, so it never contains a partially written token.
+func writeFenceFile(path string, token uint64) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(tmpFile.Name()) }()
+
+	_, err = tmpFile.WriteString(strconv.FormatUint(token, 10))
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
+
+// formatLockContent creates the content of the lock file: the fencing token and the owner of the lock.
+func formatLockContent(token uint64, owner string) string {
+	return fmt.Sprintf("%d %s\n", token, owner)
+}
+
+// readLockFile returns the fencing token and the owner stored in the lock file.
+func readLockFile(path string) (uint64, string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, "", err
+	}
+
+	parts := strings.Fields(string(content))
+	if len(parts) != lockFileParts {
+		return 0, "", fmt.Errorf("malformed lock file %s", path)
+	}
+
+	token, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("malformed fencing token in lock file %s: %w", path, err)
+	}
+
+	return token, parts[1], nil
+}
+
+// newOwnerID returns a random identifier for a FileLock instance.
+func newOwnerID() string {
+	raw := make([]byte, ownerIDBytes)
+	_, _ = rand.Read(raw)
+
+	return fmt.Sprintf("%d-%s", os.Getpid(), hex.EncodeToString(raw))
+}
@@ -0,0 +1,45 @@
+package lock
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// State describes the lock file as seen from the outside, i.e. without acquiring the lock.
+type State struct {
+	Held  bool
+	Stale bool
+	// Age is the time since the lock file was created (or touched by a take over).
+	Age   time.Duration
+	Token uint64
+	// Owner is empty if the lock file is not held or its content could not be read (e.g., it is just being written).
+	Owner string
+}
+
+// Inspect returns the state of the lock file without acquiring it.
+// Returns an error if the lock file exists but can't be accessed.
+func (l *FileLock) Inspect() (State, error) {
+	fileInfo, err := os.Stat(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return State{}, nil
+		}
+
+		return State{}, fmt.Errorf("failed to inspect lock: %w", err)
+	}
+
+	state := State{
+		Held: true,
+		Age:  time.Since(fileInfo.ModTime()),
+	}
+	state.Stale = state.Age > l.staleTimeout
+
+	// a malformed lock file is still held, its holder might not have written the token yet
+	if token, owner, err := readLockFile(l.path); err == nil {
+		state.Token = token
+		state.Owner = owner
+	}
+
+	return state, nil
+}
@@ -1,8 +1,11 @@
 package lock
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io/fs"
+	"math/rand/v2"
 	"os"
 	"time"
 
@@ -14,23 +17,60 @@ const (
 	// DefaultStaleTimeout is the default duration after which a lock is considered stale.
 	DefaultStaleTimeout = 5 * time.Minute
 
+	// DefaultMaxWait is the default maximum duration Acquire blocks before giving up.
+	DefaultMaxWait = 10 * time.Minute
+
+	// DefaultInitialBackoff is the default delay after the first failed acquisition attempt.
+	DefaultInitialBackoff = 100 * time.Millisecond
+
+	// DefaultMaxBackoff is the default upper bound for the delay between two acquisition attempts.
+	DefaultMaxBackoff = 10 * time.Second
+
+	backoffFactor = 2
+
 	filePerm600 fs.FileMode = 0o600
 )
 
+var (
+	// ErrMaxWaitExceeded is returned by Acquire if the lock could not be acquired within the max wait duration.
+	ErrMaxWaitExceeded = errors.New("max wait for the lock exceeded")
+
+	// ErrLockLost is returned by Validate if the lock file was taken over by another instance.
+	ErrLockLost = errors.New("the lock is no longer held by this instance")
+)
+
 // FileLock represents a file-based lock with stale detection.
 type FileLock struct {
-	path         string
-	staleTimeout time.Duration
-	logger       logr.Logger
+	path           string
+	staleTimeout   time.Duration
+	maxWait        time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	logger         logr.Logger
+
+	// owner uniquely identifies this FileLock instance, it is written into the lock file together with the fencing token.
+	owner string
+	// token is the fencing token of the currently held lock, 0 if the lock is not held.
+	token uint64
+}
+
+// AcquireStats describes how long Acquire waited for the lock and how many attempts were made.
+type AcquireStats struct {
+	WaitTime time.Duration
+	Attempts int
 }
 
 // New creates a new FileLock instance with the default stale timeout.
 // The lock file will be created at the specified path when the lock is acquired.
 func New(logger logr.Logger, filePath string) *FileLock {
 	return &FileLock{
-		path:         filePath,
-		staleTimeout: DefaultStaleTimeout,
-		logger:       logger,
+		path:           filePath,
+		staleTimeout:   DefaultStaleTimeout,
+		maxWait:        DefaultMaxWait,
+		initialBackoff: DefaultInitialBackoff,
+		maxBackoff:     DefaultMaxBackoff,
+		logger:         logger,
+		owner:          newOwnerID(),
 	}
 }
 
@@ -41,37 +81,121 @@ func (l *FileLock) WithStaleTimeout(timeout time.Duration) *FileLock {
 	return l
 }
 
+// WithMaxWait sets the maximum duration Acquire blocks before giving up.
+// A zero or negative value means Acquire only stops when the lock is acquired or the context is cancelled.
+func (l *FileLock) WithMaxWait(maxWait time.Duration) *FileLock {
+	l.maxWait = maxWait
+
+	return l
+}
+
+// WithBackoff sets the delay after the first failed attempt and the upper bound for the delay between attempts used by Acquire.
+// A non-positive initial delay is replaced by DefaultInitialBackoff, so Acquire never retries without a delay,
+// and the upper bound is raised to the initial delay if it is lower.
+func (l *FileLock) WithBackoff(initial, maxBackoff time.Duration) *FileLock {
+	if initial <= 0 {
+		initial = DefaultInitialBackoff
+	}
+
+	l.initialBackoff = initial
+	l.maxBackoff = max(maxBackoff, initial)
+
+	return l
+}
+
+// Acquire blocks until the lock is acquired, the context is cancelled or the max wait duration has passed.
+// Between the attempts it waits with an exponentially growing, jittered backoff, so instances started at the same time
+// do not retry in lockstep.
+// The same guarantees (and lack of them) as for TryAcquire apply to every single attempt.
+//
+// Returns the time spent waiting and the number of attempts, also in case of an error.
+// Returns ErrMaxWaitExceeded if the max wait duration has passed, or the context's error if it was cancelled.
+func (l *FileLock) Acquire(ctx context.Context) (AcquireStats, error) {
+	var stats AcquireStats
+
+	start := time.Now()
+	backoff := l.initialBackoff
+
+	if l.maxWait > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeoutCause(ctx, l.maxWait, ErrMaxWaitExceeded)
+		defer cancel()
+	}
+
+	for {
+		stats.Attempts++
+
+		acquired, err := l.TryAcquire()
+
+		stats.WaitTime = time.Since(start)
+
+		if err != nil {
+			return stats, err
+		}
+
+		if acquired {
+			log.Debug(l.logger, "Lock acquired after waiting", "path", l.path, "wait time", stats.WaitTime.String(), "attempts", stats.Attempts)
+
+			return stats, nil
+		}
+
+		delay := jitter(backoff)
+
+		log.Debug(l.logger, "Waiting before the next lock acquisition attempt", "path", l.path, "delay", delay.String(), "attempts", stats.Attempts)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			stats.WaitTime = time.Since(start)
+
+			return stats, fmt.Errorf("failed to acquire lock after %s and %d attempts: %w", stats.WaitTime, stats.Attempts, context.Cause(ctx))
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*backoffFactor, l.maxBackoff)
+	}
+}
+
 // TryAcquire attempts to acquire the lock by using an exclusive file creation lock mechanism.
-// This function does not guarantee an exclusive lock if a stale lock file is detected
-// (e.g., if a process holding the lock crashed or was forcefully terminated),
-// since removing the stale lock file and creating a new one is not atomic operation.
 // The flock syscall is not used here because it is not supported on some NFS-mounted file systems.
 //
-// Therefore, this function provides a best-effort lock, but fake lock acquisition is possible!
-// It is the caller's responsibility to handle this properly, for example, by deploying OneAgent binaries in
-// a unique work directory per process to avoid write conflicts. Only one process should then atomically rename
-// its work directory to the target location.
+// A stale lock file (e.g., if a process holding the lock crashed or was forcefully terminated) is taken over
+// by atomically renaming it to a tombstone unique to this instance, so only one instance can claim it.
+// The staleness of the claimed tombstone is validated again, because between the check and the rename,
+// another instance might have already replaced the stale lock file with a fresh one.
+// In that case, the fresh lock file is put back in place.
+//
+// Every acquired lock gets a monotonically increasing fencing token, which is stored in the lock file.
+// Before committing any change guarded by the lock, the caller should call Validate, which detects
+// if the lock was taken over by another instance in the meantime (e.g., because this instance was considered stale).
 //
 // Returns true if the lock was acquired, false if another process holds the lock.
 // Returns an error if one occurred during lock acquisition.
 func (l *FileLock) TryAcquire() (bool, error) {
-	// If the lock file was not removed in a previous run and is now stale, remove it
+	var previousToken uint64
+
+	// If the lock file was not removed in a previous run and is now stale, take it over
 	if l.isStale() {
-		log.Debug(l.logger, "Detected stale lock file, removing it", "path", l.path)
-
-		// As noted in the documentation: a race condition is still possible here because
-		// checking for staleness and removing the lock file is not atomic operation.
-		// If multiple processes detect the lock file as stale, one process might remove a new lock file created by
-		// another process in the meantime.
-		if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
-			l.logger.Info("Failed to remove stale lock file", "path", l.path, "error", err)
+		log.Debug(l.logger, "Detected stale lock file, taking it over", "path", l.path)
+
+		claimed, token := l.claimStale()
+		if !claimed {
+			l.logger.Info("Lock not acquired, another instance took over the stale lock file", "path", l.path)
+
+			return false, nil
 		}
+
+		previousToken = token
 	}
 
 	// The os.O_CREATE|os.O_EXCL flags ensures the lock file is created atomically only if it doesn't exist.
 	// The file's modification time is automatically set to the current time upon creation,
 	// which is used for stale lock detection.
-	fileLock, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL, filePerm600)
+	fileLock, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, filePerm600)
 	if err != nil {
 		if os.IsExist(err) {
 			l.logger.Info("Lock not acquired, lock file already exists", "path", l.path)
@@ -82,19 +206,76 @@ func (l *FileLock) TryAcquire() (bool, error) {
 		return false, fmt.Errorf("failed to acquire lock: %w", err)
 	}
 
-	// Close immediately, only the file's existence and timestamp metadata matter - no write is needed.
-	if err = fileLock.Close(); err != nil {
-		l.logger.Error(err, "Failed to close lock file", "path", l.path)
+	defer func() {
+		if err := fileLock.Close(); err != nil {
+			l.logger.Error(err, "Failed to close lock file", "path", l.path)
+		}
+	}()
+
+	token, err := l.nextToken(previousToken)
+	if err != nil {
+		l.removeOwnLockFile()
+
+		return false, fmt.Errorf("failed to issue fencing token: %w", err)
+	}
+
+	if _, err := fileLock.WriteString(formatLockContent(token, l.owner)); err != nil {
+		l.removeOwnLockFile()
+
+		return false, fmt.Errorf("failed to write fencing token to the lock file: %w", err)
 	}
 
-	log.Debug(l.logger, "Lock acquired successfully", "path", l.path)
+	l.token = token
+
+	log.Debug(l.logger, "Lock acquired successfully", "path", l.path, "fencing token", token)
 
 	return true, nil
 }
 
+// Token returns the fencing token of the held lock, or 0 if the lock is not held.
+func (l *FileLock) Token() uint64 {
+	return l.token
+}
+
+// Validate checks that the lock file still carries the fencing token of this instance.
+// Returns ErrLockLost if the lock is not held or was taken over by another instance.
+func (l *FileLock) Validate() error {
+	if l.token == 0 {
+		return ErrLockLost
+	}
+
+	token, owner, err := readLockFile(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("lock file is missing: %w", ErrLockLost)
+		}
+
+		return fmt.Errorf("failed to validate lock: %w", err)
+	}
+
+	if token != l.token || owner != l.owner {
+		return fmt.Errorf("lock file carries fencing token %d, expected %d: %w", token, l.token, ErrLockLost)
+	}
+
+	return nil
+}
+
 // Release removes the lock file to avoid the stale lock problem.
+// If the lock was taken over by another instance in the meantime, the lock file of the other instance is kept.
 // It is the caller's responsibility to release the lock when no longer needed.
 func (l *FileLock) Release() error {
+	if l.token != 0 {
+		err := l.Validate()
+
+		l.token = 0
+
+		if errors.Is(err, ErrLockLost) {
+			l.logger.Info("Lock was taken over by another instance, keeping its lock file", "path", l.path, "reason", err.Error())
+
+			return nil
+		}
+	}
+
 	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to release lock: %w", err)
 	}
@@ -104,6 +285,13 @@ func (l *FileLock) Release() error {
 	return nil
 }
 
+// removeOwnLockFile removes the lock file which was just created by this instance, but could not be completed.
+func (l *FileLock) removeOwnLockFile() {
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		l.logger.Error(err, "Failed to remove incomplete lock file", "path", l.path)
+	}
+}
+
 // isStale checks if the lock file exists and its modification time is older than staleTimeout.
 func (l *FileLock) isStale() bool {
 	fileInfo, err := os.Stat(l.path)
@@ -131,3 +319,13 @@ func (l *FileLock) isStale() bool {
 
 	return false
 }
+
+// jitter returns a random duration in the range [backoff/2, backoff).
+func jitter(backoff time.Duration) time.Duration {
+	half := backoff / backoffFactor
+	if half <= 0 {
+		return backoff
+	}
+
+	return half + rand.N(half) //nolint:gosec
+}
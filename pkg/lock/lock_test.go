@@ -1,6 +1,7 @@
 package lock
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"syscall"
@@ -102,7 +103,7 @@ func TestExecute(t *testing.T) {
 			require.NoError(t, fileLock.Release())
 		}()
 
-		tests.RequireLogMessage(t, logsObserver, "Detected stale lock file, removing it", "path", lockFilePath)
+		tests.RequireLogMessage(t, logsObserver, "Detected stale lock file, taking it over", "path", lockFilePath)
 	})
 
 	t.Run("TryAcquire does not remove the fresh lock", func(t *testing.T) {
@@ -255,3 +256,308 @@ func TestExecute(t *testing.T) {
 		assert.Equal(t, 1, acquiredCount)
 	})
 }
+
+func TestWithBackoff(t *testing.T) {
+	logger, _ := tests.NewTestLogger()
+
+	t.Run("non-positive initial delay is replaced by the default", func(t *testing.T) {
+		fileLock := New(logger, lockFile).WithBackoff(0, -time.Second)
+
+		assert.Equal(t, DefaultInitialBackoff, fileLock.initialBackoff)
+		assert.Equal(t, DefaultInitialBackoff, fileLock.maxBackoff)
+	})
+
+	t.Run("upper bound is raised to the initial delay", func(t *testing.T) {
+		fileLock := New(logger, lockFile).WithBackoff(time.Second, time.Millisecond)
+
+		assert.Equal(t, time.Second, fileLock.initialBackoff)
+		assert.Equal(t, time.Second, fileLock.maxBackoff)
+	})
+}
+
+func TestAcquire(t *testing.T) {
+	t.Run("Acquire acquires a free lock on the first attempt", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		fileLock := New(logger, lockFilePath)
+
+		stats, err := fileLock.Acquire(context.Background())
+		require.NoError(t, err)
+		// cleanup
+		defer func() {
+			require.NoError(t, fileLock.Release())
+		}()
+
+		assert.Equal(t, 1, stats.Attempts)
+		assert.FileExists(t, lockFilePath)
+	})
+
+	t.Run("Acquire waits until the lock is released", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		holder := New(logger, lockFilePath)
+
+		acquired, err := holder.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		const holdDuration = 300 * time.Millisecond
+
+		go func() {
+			time.Sleep(holdDuration)
+			assert.NoError(t, holder.Release())
+		}()
+
+		fileLock := New(logger, lockFilePath).WithBackoff(10*time.Millisecond, 50*time.Millisecond)
+		stats, err := fileLock.Acquire(context.Background())
+		require.NoError(t, err)
+		// cleanup
+		defer func() {
+			require.NoError(t, fileLock.Release())
+		}()
+
+		assert.Greater(t, stats.Attempts, 1)
+		assert.GreaterOrEqual(t, stats.WaitTime, holdDuration)
+	})
+
+	t.Run("Acquire gives up after the max wait", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		holder := New(logger, lockFilePath)
+
+		acquired, err := holder.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+		// cleanup
+		defer func() {
+			require.NoError(t, holder.Release())
+		}()
+
+		const maxWait = 200 * time.Millisecond
+
+		fileLock := New(logger, lockFilePath).WithMaxWait(maxWait).WithBackoff(10*time.Millisecond, 50*time.Millisecond)
+		stats, err := fileLock.Acquire(context.Background())
+		require.ErrorIs(t, err, ErrMaxWaitExceeded)
+		assert.Greater(t, stats.Attempts, 1)
+		assert.GreaterOrEqual(t, stats.WaitTime, maxWait)
+	})
+
+	t.Run("Acquire stops when the context is cancelled", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		holder := New(logger, lockFilePath)
+
+		acquired, err := holder.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+		// cleanup
+		defer func() {
+			require.NoError(t, holder.Release())
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		fileLock := New(logger, lockFilePath).WithBackoff(10*time.Millisecond, 50*time.Millisecond)
+		_, err = fileLock.Acquire(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NotErrorIs(t, err, ErrMaxWaitExceeded)
+	})
+
+	t.Run("Acquire returns the error of a failed attempt", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := "/nonexisting/directory/test.lock"
+		fileLock := New(logger, lockFilePath)
+
+		stats, err := fileLock.Acquire(context.Background())
+		require.ErrorIs(t, err, syscall.ENOENT)
+		assert.Equal(t, 1, stats.Attempts)
+	})
+
+	t.Run("jitter stays within [backoff/2, backoff)", func(t *testing.T) {
+		const backoff = 100 * time.Millisecond
+
+		for range 100 {
+			delay := jitter(backoff)
+			assert.GreaterOrEqual(t, delay, backoff/2)
+			assert.Less(t, delay, backoff)
+		}
+	})
+}
+
+func TestFencing(t *testing.T) {
+	t.Run("Fencing tokens increase with every acquisition", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+
+		var lastToken uint64
+
+		for range 3 {
+			fileLock := New(logger, lockFilePath)
+
+			acquired, err := fileLock.TryAcquire()
+			require.NoError(t, err)
+			require.True(t, acquired)
+			require.Greater(t, fileLock.Token(), lastToken)
+			require.NoError(t, fileLock.Validate())
+
+			lastToken = fileLock.Token()
+
+			require.NoError(t, fileLock.Release())
+			assert.Equal(t, uint64(0), fileLock.Token())
+		}
+	})
+
+	t.Run("A malformed fence file is overwritten", func(t *testing.T) {
+		logger, logsObserver := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		require.NoError(t, os.WriteFile(lockFilePath+fenceFileSuffix, []byte("garbage"), 0o600))
+
+		fileLock := New(logger, lockFilePath)
+
+		acquired, err := fileLock.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+		assert.Equal(t, uint64(1), fileLock.Token())
+		require.NoError(t, fileLock.Release())
+
+		tests.RequireLogMessage(t, logsObserver, "Fence file is malformed, overwriting it")
+
+		token, err := readFenceFile(lockFilePath + fenceFileSuffix)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), token)
+	})
+
+	t.Run("Validate fails if the lock is not held", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		fileLock := New(logger, filepath.Join(t.TempDir(), lockFile))
+
+		require.ErrorIs(t, fileLock.Validate(), ErrLockLost)
+	})
+
+	t.Run("Stale lock takeover is detected by the previous holder", func(t *testing.T) {
+		logger, logsObserver := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		customStaleTimeout := 500 * time.Millisecond
+
+		staleLock := New(logger, lockFilePath)
+		acquired, err := staleLock.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		time.Sleep(customStaleTimeout + 100*time.Millisecond)
+
+		fileLock := New(logger, lockFilePath).WithStaleTimeout(customStaleTimeout)
+		acquired, err = fileLock.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+		require.Greater(t, fileLock.Token(), staleLock.Token())
+
+		// the previous holder must not commit anything anymore
+		require.ErrorIs(t, staleLock.Validate(), ErrLockLost)
+		require.NoError(t, fileLock.Validate())
+
+		// releasing the lost lock must keep the lock file of the new holder
+		require.NoError(t, staleLock.Release())
+		tests.RequireLogMessage(t, logsObserver, "Lock was taken over by another instance, keeping its lock file")
+		require.NoError(t, fileLock.Validate())
+
+		require.NoError(t, fileLock.Release())
+		assert.NoFileExists(t, lockFilePath)
+	})
+
+	t.Run("A fresh lock claimed by mistake is restored", func(t *testing.T) {
+		logger, logsObserver := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+
+		holder := New(logger, lockFilePath)
+		acquired, err := holder.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+		// cleanup
+		defer func() {
+			require.NoError(t, holder.Release())
+		}()
+
+		// simulate an instance which detected the lock as stale right before the holder replaced it
+		fileLock := New(logger, lockFilePath)
+		claimed, _ := fileLock.claimStale()
+		require.False(t, claimed)
+
+		tests.RequireLogMessage(t, logsObserver, "Claimed lock file is not stale anymore, restoring it", "path", lockFilePath)
+		require.NoError(t, holder.Validate())
+
+		// no tombstone must be left behind
+		entries, err := os.ReadDir(filepath.Dir(lockFilePath))
+		require.NoError(t, err)
+
+		for _, entry := range entries {
+			assert.NotContains(t, entry.Name(), tombstoneSuffix)
+		}
+	})
+
+	t.Run("Claiming an already removed stale lock succeeds", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		fileLock := New(logger, filepath.Join(t.TempDir(), lockFile))
+
+		claimed, token := fileLock.claimStale()
+		require.True(t, claimed)
+		require.Equal(t, uint64(0), token)
+	})
+}
+
+func TestInspect(t *testing.T) {
+	t.Run("lock file does not exist", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		state, err := New(logger, filepath.Join(t.TempDir(), lockFile)).Inspect()
+		require.NoError(t, err)
+		assert.Equal(t, State{}, state)
+	})
+
+	t.Run("lock is held", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		holder := New(logger, lockFilePath)
+
+		acquired, err := holder.TryAcquire()
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		state, err := New(logger, lockFilePath).Inspect()
+		require.NoError(t, err)
+		assert.True(t, state.Held)
+		assert.False(t, state.Stale)
+		assert.Equal(t, holder.Token(), state.Token)
+		assert.Equal(t, holder.owner, state.Owner)
+	})
+
+	t.Run("lock is stale", func(t *testing.T) {
+		logger, _ := tests.NewTestLogger()
+
+		lockFilePath := filepath.Join(t.TempDir(), lockFile)
+		require.NoError(t, os.WriteFile(lockFilePath, []byte{}, 0o600))
+
+		oldTime := time.Now().Add(-10 * time.Minute)
+		require.NoError(t, os.Chtimes(lockFilePath, oldTime, oldTime))
+
+		state, err := New(logger, lockFilePath).Inspect()
+		require.NoError(t, err)
+		assert.True(t, state.Held)
+		assert.True(t, state.Stale)
+		assert.Zero(t, state.Token)
+		assert.Empty(t, state.Owner)
+	})
+}
@@ -0,0 +1,83 @@
+package lock
+
+import (
+	"os"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
+)
+
+const tombstoneSuffix = ".tombstone"
+
+// claimStale takes over a stale lock file without relying on flock.
+// The stale lock file is atomically renamed to a tombstone unique to this instance, so only a single instance
+// can claim a given lock file. As another instance might have replaced the stale lock file with a fresh one
+// between the staleness check and the rename, the staleness of the tombstone is validated again.
+// A fresh lock file is put back in place, otherwise the tombstone is removed.
+//
+// Returns true if the stale lock file was claimed (or was already gone) and the fencing token found in it.
+func (l *FileLock) claimStale() (bool, uint64) {
+	tombstone := l.tombstonePath()
+
+	if err := os.Rename(l.path, tombstone); err != nil {
+		if os.IsNotExist(err) {
+			log.Debug(l.logger, "Stale lock file is already gone", "path", l.path)
+
+			return true, 0
+		}
+
+		l.logger.Info("Failed to claim stale lock file", "path", l.path, "error", err)
+
+		return false, 0
+	}
+
+	if !l.isTombstoneStale(tombstone) {
+		l.restoreTombstone(tombstone)
+
+		return false, 0
+	}
+
+	// the token is only used to keep the fencing tokens monotonic, an unreadable token is not fatal
+	token, _, err := readLockFile(tombstone)
+	if err != nil {
+		log.Debug(l.logger, "Failed to read fencing token from the stale lock file", "path", tombstone, "error", err.Error())
+	}
+
+	if err := os.Remove(tombstone); err != nil && !os.IsNotExist(err) {
+		l.logger.Info("Failed to remove stale lock tombstone", "path", tombstone, "error", err)
+	}
+
+	log.Debug(l.logger, "Stale lock file claimed", "path", l.path, "stale fencing token", token)
+
+	return true, token
+}
+
+// isTombstoneStale re-validates the staleness of the claimed lock file.
+func (l *FileLock) isTombstoneStale(tombstone string) bool {
+	fileInfo, err := os.Stat(tombstone)
+	if err != nil {
+		// the tombstone is unique to this instance, if it can't be accessed, there is nothing to protect
+		return true
+	}
+
+	return time.Since(fileInfo.ModTime()) > l.staleTimeout
+}
+
+// restoreTombstone puts a fresh lock file, which was claimed by mistake, back in place.
+// os.Link fails if a lock file was created in the meantime, so a newer lock file is never overwritten.
+// In that case, the owner of the claimed lock file detects the loss of the lock via its fencing token.
+func (l *FileLock) restoreTombstone(tombstone string) {
+	l.logger.Info("Claimed lock file is not stale anymore, restoring it", "path", l.path)
+
+	if err := os.Link(tombstone, l.path); err != nil {
+		l.logger.Info("Failed to restore the claimed lock file", "path", l.path, "error", err)
+	}
+
+	if err := os.Remove(tombstone); err != nil && !os.IsNotExist(err) {
+		l.logger.Info("Failed to remove lock tombstone", "path", tombstone, "error", err)
+	}
+}
+
+func (l *FileLock) tombstonePath() string {
+	return l.path + "." + l.owner + tombstoneSuffix
+}
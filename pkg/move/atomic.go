@@ -1,13 +1,14 @@
 package move
 
 import (
+	"context"
 	"os"
 
 	"github.com/go-logr/logr"
 )
 
 func Atomic(work string, copyFunc CopyFunc) CopyFunc {
-	return func(log logr.Logger, from, to string) (err error) {
+	return func(ctx context.Context, log logr.Logger, from, to string) (err error) {
 		log.Info("setting up atomic operation", "from", from, "to", to, "work", work)
 
 		err = os.RemoveAll(work)
@@ -32,7 +33,7 @@ func Atomic(work string, copyFunc CopyFunc) CopyFunc {
 			}
 		}()
 
-		err = copyFunc(log, from, work)
+		err = copyFunc(ctx, log, from, work)
 		if err != nil {
 			log.Error(err, "error copying folder")
 
@@ -1,6 +1,7 @@
 package move
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -18,7 +19,7 @@ var testLog = zapr.NewLogger(zap.NewExample())
 func mockCopyFuncWithAtomicCheck(t *testing.T, workFolder string, isSuccessful bool) CopyFunc {
 	t.Helper()
 
-	return func(_ logr.Logger, _, target string) error {
+	return func(_ context.Context, _ logr.Logger, _, target string) error {
 		// according to the inner copyFunc, the target should be the workFolder
 		// the actual target will be created outside the copyFunc by the atomic wrapper using fs.Rename
 		require.Equal(t, workFolder, target)
@@ -50,7 +51,7 @@ func TestAtomic(t *testing.T) {
 
 		atomicCopy := Atomic(work, mockCopyFuncWithAtomicCheck(t, work, true))
 
-		err = atomicCopy(testLog, source, target)
+		err = atomicCopy(t.Context(), testLog, source, target)
 		require.NoError(t, err)
 
 		require.NotEqual(t, work, target)
@@ -70,7 +71,7 @@ func TestAtomic(t *testing.T) {
 
 		atomicCopy := Atomic(work, mockCopyFuncWithAtomicCheck(t, work, false))
 
-		err := atomicCopy(testLog, source, target)
+		err := atomicCopy(t.Context(), testLog, source, target)
 		require.Error(t, err)
 		assert.Equal(t, "some mock error", err.Error())
 
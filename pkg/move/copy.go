@@ -1,6 +1,8 @@
 package move
 
 import (
+	"context"
+
 	fsutils "github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/fs"
 	"github.com/go-logr/logr"
 	"golang.org/x/sys/unix"
@@ -10,17 +12,17 @@ const (
 	noPermissionsMask = 0000
 )
 
-type CopyFunc func(log logr.Logger, from, to string) error
+type CopyFunc func(ctx context.Context, log logr.Logger, from, to string) error
 
 var _ CopyFunc = SimpleCopy
 
-func SimpleCopy(log logr.Logger, from, to string) error {
+func SimpleCopy(ctx context.Context, log logr.Logger, from, to string) error {
 	log.Info("starting to copy (simple)", "from", from, "to", to)
 
 	oldUmask := unix.Umask(noPermissionsMask)
 	defer unix.Umask(oldUmask)
 
-	err := fsutils.CopyFolder(log, from, to)
+	err := fsutils.CopyFolder(ctx, log, from, to)
 	if err != nil {
 		log.Error(err, "error moving folder")
 
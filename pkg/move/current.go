@@ -1,6 +1,7 @@
 package move
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -44,8 +45,8 @@ func CreateCurrentSymlink(log logr.Logger, targetDir string) error {
 // CreateCurrentSymlinkOnCopy wraps the given copy function to create the current symlink right after the copy operation.
 // The copy wrapper is used to create the current symlink in the working directory before it is moved to the target directory.
 func CreateCurrentSymlinkOnCopy(copyFunc CopyFunc) CopyFunc {
-	return func(log logr.Logger, from, to string) (err error) {
-		err = copyFunc(log, from, to)
+	return func(ctx context.Context, log logr.Logger, from, to string) (err error) {
+		err = copyFunc(ctx, log, from, to)
 		if err != nil {
 			return err
 		}
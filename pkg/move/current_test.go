@@ -59,7 +59,7 @@ func TestCreateCurrentSymlink(t *testing.T) {
 		copyFunc := CreateCurrentSymlinkOnCopy(SimpleCopy)
 
 		targetDir := t.TempDir()
-		err := copyFunc(testLog, sourceDir, targetDir)
+		err := copyFunc(t.Context(), testLog, sourceDir, targetDir)
 		require.NoError(t, err)
 
 		// check if the symlink exists after the copy operation
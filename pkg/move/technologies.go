@@ -1,6 +1,7 @@
 package move
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
@@ -28,12 +29,12 @@ type FileEntry struct {
 }
 
 func CopyByTechnologyWrapper(technology string) CopyFunc {
-	return func(log logr.Logger, from, to string) error {
-		return CopyByTechnology(log, from, to, technology)
+	return func(ctx context.Context, log logr.Logger, from, to string) error {
+		return CopyByTechnology(ctx, log, from, to, technology)
 	}
 }
 
-func CopyByTechnology(log logr.Logger, from string, to string, technology string) error {
+func CopyByTechnology(ctx context.Context, log logr.Logger, from string, to string, technology string) error {
 	log.Info("starting to copy (filtered)", "from", from, "to", to, "technology", technology)
 
 	filteredPaths, err := filterFilesByTechnology(log, from, strings.Split(technology, ","))
@@ -41,7 +42,7 @@ func CopyByTechnology(log logr.Logger, from string, to string, technology string
 		return err
 	}
 
-	err = copyByList(log, from, to, filteredPaths)
+	err = copyByList(ctx, log, from, to, filteredPaths)
 	if err != nil {
 		return err
 	}
@@ -51,7 +52,7 @@ func CopyByTechnology(log logr.Logger, from string, to string, technology string
 	return nil
 }
 
-func copyByList(log logr.Logger, from string, to string, paths []string) error {
+func copyByList(ctx context.Context, log logr.Logger, from string, to string, paths []string) error {
 	oldUmask := unix.Umask(noPermissionsMask)
 	defer unix.Umask(oldUmask)
 
@@ -70,6 +71,10 @@ func copyByList(log logr.Logger, from string, to string, paths []string) error {
 	}
 
 	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return errors.WithStack(err)
+		}
+
 		splitPath := strings.Split(path, string(filepath.Separator))
 		walkedPath := ""
 
@@ -100,7 +105,7 @@ func copyByList(log logr.Logger, from string, to string, paths []string) error {
 
 			log.V(1).Info("copying file", "from", sourcePath, "to", targetPath, "mode", sourceStat.Mode())
 
-			err = fsutils.CopyFile(sourcePath, targetPath)
+			err = fsutils.CopyFile(ctx, sourcePath, targetPath)
 			if err != nil {
 				log.Error(err, "error copying file")
 
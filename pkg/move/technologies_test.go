@@ -51,7 +51,7 @@ func TestCopyFolderWithTechnologyFiltering(t *testing.T) {
 		})
 
 		technology := "java"
-		err := CopyByTechnology(testLog, sourceDir, targetDir, technology)
+		err := CopyByTechnology(t.Context(), testLog, sourceDir, targetDir, technology)
 		require.NoError(t, err)
 
 		assert.FileExists(t, filepath.Join(targetDir, "fileA1.txt"))
@@ -66,7 +66,7 @@ func TestCopyFolderWithTechnologyFiltering(t *testing.T) {
 		})
 
 		technology := "java,python"
-		err := CopyByTechnology(testLog, sourceDir, targetDir, technology)
+		err := CopyByTechnology(t.Context(), testLog, sourceDir, targetDir, technology)
 		require.NoError(t, err)
 
 		assert.FileExists(t, filepath.Join(targetDir, "fileA1.txt"))
@@ -81,7 +81,7 @@ func TestCopyFolderWithTechnologyFiltering(t *testing.T) {
 		})
 
 		technology := "java, python"
-		err := CopyByTechnology(testLog, sourceDir, targetDir, technology)
+		err := CopyByTechnology(t.Context(), testLog, sourceDir, targetDir, technology)
 		require.NoError(t, err)
 
 		assert.FileExists(t, filepath.Join(targetDir, "fileA1.txt"))
@@ -96,7 +96,7 @@ func TestCopyFolderWithTechnologyFiltering(t *testing.T) {
 		})
 
 		technology := "php"
-		err := CopyByTechnology(testLog, sourceDir, targetDir, technology)
+		err := CopyByTechnology(t.Context(), testLog, sourceDir, targetDir, technology)
 		require.NoError(t, err)
 
 		assert.NoFileExists(t, filepath.Join(targetDir, "fileA1.txt"))
@@ -154,7 +154,7 @@ func TestCopyByList(t *testing.T) {
 
 	targetDir := filepath.Join(tmpDir, "target")
 
-	err := copyByList(testLog, sourceDir, targetDir, fileList)
+	err := copyByList(t.Context(), testLog, sourceDir, targetDir, fileList)
 	require.NoError(t, err)
 
 	for i := range dirs {
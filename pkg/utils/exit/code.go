@@ -0,0 +1,48 @@
+package exit
+
+import "errors"
+
+const (
+	// Success is the exit code if no error occurred.
+	Success = 0
+	// Failure is the default exit code in case of an error.
+	Failure = 1
+)
+
+// Error is an error which requests the process to exit with a specific code.
+type Error struct {
+	Err  error
+	Code int
+}
+
+// WithCode wraps the error, so the process exits with the given code.
+func WithCode(code int, err error) error {
+	return &Error{Code: code, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return ""
+	}
+
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the exit code for the given error: Success if it is nil,
+// the code of the wrapped Error if there is one, Failure otherwise.
+func Code(err error) int {
+	if err == nil {
+		return Success
+	}
+
+	var exitErr *Error
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return Failure
+}
@@ -0,0 +1,35 @@
+package exit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	someErr := errors.New("some error")
+
+	t.Run("no error -> success", func(t *testing.T) {
+		assert.Equal(t, Success, Code(nil))
+	})
+
+	t.Run("plain error -> failure", func(t *testing.T) {
+		assert.Equal(t, Failure, Code(someErr))
+	})
+
+	t.Run("error with code -> code", func(t *testing.T) {
+		err := WithCode(3, someErr)
+
+		assert.Equal(t, 3, Code(err))
+		assert.ErrorIs(t, err, someErr)
+		assert.Equal(t, someErr.Error(), err.Error())
+	})
+
+	t.Run("wrapped error with code -> code", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", WithCode(4, someErr))
+
+		assert.Equal(t, 4, Code(err))
+	})
+}
@@ -0,0 +1,191 @@
+package flags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileFlag is the name of the flag with the path to the config file.
+const ConfigFileFlag = "config"
+
+// JSONAnnotation marks a repeatable flag whose values are JSON objects (e.g., --attribute-container),
+// so its items can be given as objects in the config file, instead of JSON strings.
+const JSONAnnotation = "dynatrace-bootstrapper/json"
+
+// ignoredFlags are not configurable via the config file.
+var ignoredFlags = append([]string{ConfigFileFlag}, builtinFlags...)
+
+// AddConfigFileFlag adds the --config flag to the command, it has to be applied by ApplyConfigFile before the command runs.
+func AddConfigFileFlag(cmd *cobra.Command) {
+	cmd.Flags().String(ConfigFileFlag, "", "(Optional) Path to a YAML or JSON file with the values of the other flags (by their name). Explicitly set flags and environment variables take precedence over the file.")
+}
+
+// ApplyConfigFile sets the flags of the command, which are not set explicitly, to the values in the config file given by the --config flag.
+// The config file is validated against the schema of the flags (see Schema), all invalid fields are reported at once.
+// It has to be called before the required flags are validated, i.e. in the PreRunE of the command.
+func ApplyConfigFile(cmd *cobra.Command) error {
+	path, err := cmd.Flags().GetString(ConfigFileFlag)
+	if err != nil || path == "" {
+		return err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read the config file: %w", err)
+	}
+
+	// JSON is a subset of YAML, so both can be parsed the same way
+	var config map[string]any
+	if err := yaml.Unmarshal(raw, &config); err != nil {
+		return fmt.Errorf("failed to parse the config file %s: %w", path, err)
+	}
+
+	values, err := validate(cmd.Flags(), config)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s:\n%w", path, err)
+	}
+
+	for _, name := range sortedKeys(values) {
+		if cmd.Flags().Changed(name) {
+			continue
+		}
+
+		for _, value := range values[name] {
+			if err := cmd.Flags().Set(name, value); err != nil {
+				return fmt.Errorf("invalid config file %s: %s: %w", path, name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validate checks every field of the config against the type of its flag, and returns the values of the fields as flag values.
+func validate(flagSet *pflag.FlagSet, config map[string]any) (map[string][]string, error) {
+	values := make(map[string][]string, len(config))
+
+	var errs []error
+
+	for _, name := range sortedKeys(config) {
+		flag := flagSet.Lookup(name)
+		if flag == nil || slices.Contains(ignoredFlags, name) {
+			errs = append(errs, fmt.Errorf("%s: unknown field", name))
+
+			continue
+		}
+
+		fieldValues, err := toFlagValues(flag, config[name])
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		values[name] = fieldValues
+	}
+
+	return values, errors.Join(errs...)
+}
+
+func toFlagValues(flag *pflag.Flag, value any) ([]string, error) {
+	switch flag.Value.Type() {
+	case "bool":
+		if b, ok := value.(bool); ok {
+			return []string{strconv.FormatBool(b)}, nil
+		}
+
+		return nil, fmt.Errorf("%s: must be a boolean", flag.Name)
+	case "int", "int64", "uint", "uint64":
+		if i, ok := value.(int); ok {
+			return []string{strconv.Itoa(i)}, nil
+		}
+
+		return nil, fmt.Errorf("%s: must be an integer", flag.Name)
+	case "duration":
+		if s, ok := value.(string); ok {
+			if _, err := time.ParseDuration(s); err == nil {
+				return []string{s}, nil
+			}
+		}
+
+		return nil, fmt.Errorf("%s: must be a duration (e.g., \"10s\")", flag.Name)
+	case "stringArray", "stringSlice":
+		return toFlagArrayValues(flag, value)
+	default:
+		if s, ok := value.(string); ok {
+			return []string{s}, nil
+		}
+
+		return nil, fmt.Errorf("%s: must be a string", flag.Name)
+	}
+}
+
+func toFlagArrayValues(flag *pflag.Flag, value any) ([]string, error) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: must be a list", flag.Name)
+	}
+
+	values := make([]string, 0, len(items))
+
+	for i, item := range items {
+		switch typed := item.(type) {
+		case string:
+			values = append(values, typed)
+		case map[string]any:
+			if !isJSONFlag(flag) {
+				return nil, fmt.Errorf("%s[%d]: must be a string", flag.Name, i)
+			}
+
+			raw, err := json.Marshal(typed)
+			if err != nil {
+				return nil, fmt.Errorf("%s[%d]: %w", flag.Name, i, err)
+			}
+
+			values = append(values, string(raw))
+		default:
+			if isJSONFlag(flag) {
+				return nil, fmt.Errorf("%s[%d]: must be an object or a JSON string", flag.Name, i)
+			}
+
+			return nil, fmt.Errorf("%s[%d]: must be a string", flag.Name, i)
+		}
+	}
+
+	return values, nil
+}
+
+func isArrayFlag(flag *pflag.Flag) bool {
+	switch flag.Value.Type() {
+	case "stringArray", "stringSlice":
+		return true
+	default:
+		return false
+	}
+}
+
+func isJSONFlag(flag *pflag.Flag) bool {
+	_, ok := flag.Annotations[JSONAnnotation]
+
+	return ok
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
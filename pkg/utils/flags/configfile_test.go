@@ -0,0 +1,183 @@
+package flags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFlags struct {
+	target     string
+	attributes []string
+	containers []string
+	interval   time.Duration
+	debug      bool
+}
+
+func newTestCmd(t *testing.T, values *testFlags) *cobra.Command {
+	t.Helper()
+
+	cmd := &cobra.Command{
+		Use:     "test",
+		PreRunE: func(cmd *cobra.Command, _ []string) error { return ApplyConfigFile(cmd) },
+		RunE:    func(*cobra.Command, []string) error { return nil },
+	}
+
+	cmd.Flags().StringVar(&values.target, "target", "", "target")
+	require.NoError(t, cmd.MarkFlagRequired("target"))
+	cmd.Flags().StringArrayVar(&values.attributes, "attribute", []string{}, "attribute")
+	cmd.Flags().StringArrayVar(&values.containers, "attribute-container", []string{}, "container")
+	require.NoError(t, cmd.Flags().SetAnnotation("attribute-container", JSONAnnotation, []string{"true"}))
+	cmd.Flags().DurationVar(&values.interval, "interval", time.Second, "interval")
+	cmd.Flags().BoolVar(&values.debug, "debug", false, "debug")
+	AddConfigFileFlag(cmd)
+
+	return cmd
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	t.Run("YAML config file sets the flags", func(t *testing.T) {
+		configFile := writeConfigFile(t, "config.yaml", `
+target: /target
+attribute:
+  - k8s.pod.name=pod
+  - k8s.namespace.name=ns
+attribute-container:
+  - k8s.container.name: app
+  - '{"k8s.container.name":"sidecar"}'
+interval: 1m
+debug: true
+`)
+
+		var values testFlags
+
+		cmd := newTestCmd(t, &values)
+		cmd.SetArgs([]string{"--config", configFile})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, "/target", values.target)
+		assert.Equal(t, []string{"k8s.pod.name=pod", "k8s.namespace.name=ns"}, values.attributes)
+		assert.Equal(t, []string{`{"k8s.container.name":"app"}`, `{"k8s.container.name":"sidecar"}`}, values.containers)
+		assert.Equal(t, time.Minute, values.interval)
+		assert.True(t, values.debug)
+	})
+
+	t.Run("JSON config file sets the flags", func(t *testing.T) {
+		configFile := writeConfigFile(t, "config.json", `{"target": "/target", "attribute": ["k8s.pod.name=pod"]}`)
+
+		var values testFlags
+
+		cmd := newTestCmd(t, &values)
+		cmd.SetArgs([]string{"--config", configFile})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, "/target", values.target)
+		assert.Equal(t, []string{"k8s.pod.name=pod"}, values.attributes)
+	})
+
+	t.Run("explicit flags take precedence", func(t *testing.T) {
+		configFile := writeConfigFile(t, "config.yaml", "target: /target\nattribute: [k8s.pod.name=pod]\n")
+
+		var values testFlags
+
+		cmd := newTestCmd(t, &values)
+		cmd.SetArgs([]string{"--config", configFile, "--target", "/explicit", "--attribute", "k8s.pod.name=explicit"})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, "/explicit", values.target)
+		assert.Equal(t, []string{"k8s.pod.name=explicit"}, values.attributes)
+	})
+
+	t.Run("invalid fields are reported at once", func(t *testing.T) {
+		configFile := writeConfigFile(t, "config.yaml", `
+target: [/target]
+attribute:
+  - name: value
+attribute-container: [1]
+interval: soon
+debug: "yes"
+unknown: value
+config: other.yaml
+`)
+
+		var values testFlags
+
+		cmd := newTestCmd(t, &values)
+		cmd.SetArgs([]string{"--config", configFile})
+		cmd.SilenceUsage = true
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "target: must be a string")
+		assert.ErrorContains(t, err, "attribute[0]: must be a string")
+		assert.ErrorContains(t, err, "attribute-container[0]: must be an object or a JSON string")
+		assert.ErrorContains(t, err, `interval: must be a duration (e.g., "10s")`)
+		assert.ErrorContains(t, err, "debug: must be a boolean")
+		assert.ErrorContains(t, err, "unknown: unknown field")
+		assert.ErrorContains(t, err, "config: unknown field")
+	})
+
+	t.Run("malformed config file", func(t *testing.T) {
+		configFile := writeConfigFile(t, "config.yaml", "target: [")
+
+		var values testFlags
+
+		cmd := newTestCmd(t, &values)
+		cmd.SetArgs([]string{"--config", configFile})
+		cmd.SilenceUsage = true
+
+		require.ErrorContains(t, cmd.Execute(), "failed to parse the config file")
+	})
+
+	t.Run("missing config file", func(t *testing.T) {
+		var values testFlags
+
+		cmd := newTestCmd(t, &values)
+		cmd.SetArgs([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")})
+		cmd.SilenceUsage = true
+
+		require.ErrorContains(t, cmd.Execute(), "failed to read the config file")
+	})
+
+	t.Run("required flags are still validated", func(t *testing.T) {
+		configFile := writeConfigFile(t, "config.yaml", "debug: true\n")
+
+		var values testFlags
+
+		cmd := newTestCmd(t, &values)
+		cmd.SetArgs([]string{"--config", configFile})
+		cmd.SilenceUsage = true
+
+		require.ErrorContains(t, cmd.Execute(), `required flag(s) "target" not set`)
+	})
+}
+
+func TestSchema(t *testing.T) {
+	var values testFlags
+
+	schema := Schema("test", newTestCmd(t, &values).Flags())
+
+	assert.Equal(t, false, schema["additionalProperties"])
+
+	properties, ok := schema["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.NotContains(t, properties, ConfigFileFlag)
+	assert.Equal(t, map[string]any{"type": "string", "description": "target"}, properties["target"])
+	assert.Equal(t, map[string]any{"type": "boolean", "description": "debug"}, properties["debug"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "attribute"}, properties["attribute"])
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": []string{"object", "string"}}, "description": "container"}, properties["attribute-container"])
+}
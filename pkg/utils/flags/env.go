@@ -0,0 +1,141 @@
+package flags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/log"
+	"github.com/go-logr/logr"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// EnvPrefix is the prefix of the environment variables bound to the flags, e.g. DT_BOOTSTRAPPER_SOURCE for --source.
+const EnvPrefix = "DT_BOOTSTRAPPER_"
+
+// The sources of the effective value of a flag, in the order of their precedence.
+const (
+	SourceFlag       = "flag"
+	SourceEnv        = "env"
+	SourceConfigFile = "config file"
+	SourceDefault    = "default"
+)
+
+const sourceAnnotation = "dynatrace-bootstrapper/source"
+
+// builtinFlags are added by cobra, and are not bound to environment variables.
+var builtinFlags = []string{"help", "version"}
+
+// EnvName returns the name of the environment variable bound to the flag.
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// Apply sets the flags of the command, which are not set explicitly, from the environment variables (see ApplyEnv)
+// and then from the config file (see ApplyConfigFile), so the precedence is: flag > env > config file > default.
+// The source of every flag is recorded, so it can be logged via LogSources once the logger is set up.
+// It has to be called before the required flags are validated, i.e. in the PreRunE of the command.
+func Apply(cmd *cobra.Command) error {
+	markSources(cmd.Flags(), SourceFlag)
+
+	if err := ApplyEnv(cmd); err != nil {
+		return err
+	}
+
+	markSources(cmd.Flags(), SourceEnv)
+
+	if err := ApplyConfigFile(cmd); err != nil {
+		return err
+	}
+
+	markSources(cmd.Flags(), SourceConfigFile)
+
+	return nil
+}
+
+// ApplyEnv sets the flags of the command, which are not set explicitly, to the values of their environment variables (see EnvName).
+// Empty environment variables are ignored.
+// Repeatable flags (e.g., --attribute) take a JSON list (e.g., `["k8s.pod.name=app","k8s.namespace.name=default"]`),
+// any other value is taken as a single item. All invalid environment variables are reported at once.
+func ApplyEnv(cmd *cobra.Command) error {
+	var errs []error
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || slices.Contains(builtinFlags, flag.Name) {
+			return
+		}
+
+		envName := EnvName(flag.Name)
+
+		value, ok := os.LookupEnv(envName)
+		if !ok || value == "" {
+			return
+		}
+
+		values, err := toEnvFlagValues(flag, value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", envName, err))
+
+			return
+		}
+
+		for _, value := range values {
+			if err := cmd.Flags().Set(flag.Name, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", envName, err))
+
+				return
+			}
+		}
+	})
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid environment variables:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func toEnvFlagValues(flag *pflag.Flag, value string) ([]string, error) {
+	if !isArrayFlag(flag) || !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		return []string{value}, nil
+	}
+
+	var items any
+	if err := json.Unmarshal([]byte(value), &items); err != nil {
+		return nil, fmt.Errorf("must be a JSON list: %w", err)
+	}
+
+	return toFlagArrayValues(flag, items)
+}
+
+// LogSources logs where the value of every flag came from (flag, env, config file or default).
+// The values are not logged, as some of them (e.g. the attributes or the proxy) may contain credentials.
+func LogSources(logger logr.Logger, cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if slices.Contains(builtinFlags, flag.Name) {
+			return
+		}
+
+		source := SourceDefault
+		if sources := flag.Annotations[sourceAnnotation]; len(sources) > 0 {
+			source = sources[0]
+		}
+
+		log.Debug(logger, "flag source", "flag", flag.Name, "source", source)
+	})
+}
+
+// markSources records the source of the flags, which are set, but have no source yet.
+func markSources(flagSet *pflag.FlagSet, source string) {
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		if _, ok := flag.Annotations[sourceAnnotation]; ok || !flag.Changed {
+			return
+		}
+
+		_ = flagSet.SetAnnotation(flag.Name, sourceAnnotation, []string{source})
+	})
+}
@@ -0,0 +1,130 @@
+package flags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/utils/tests"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEnvCmd(t *testing.T, values *testFlags) *cobra.Command {
+	t.Helper()
+
+	cmd := newTestCmd(t, values)
+	cmd.PreRunE = func(cmd *cobra.Command, _ []string) error { return Apply(cmd) }
+
+	return cmd
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "DT_BOOTSTRAPPER_TARGET", EnvName("target"))
+	assert.Equal(t, "DT_BOOTSTRAPPER_ATTRIBUTE_CONTAINER", EnvName("attribute-container"))
+}
+
+func TestApplyEnv(t *testing.T) {
+	t.Run("environment variables set the flags", func(t *testing.T) {
+		t.Setenv("DT_BOOTSTRAPPER_TARGET", "/target")
+		t.Setenv("DT_BOOTSTRAPPER_ATTRIBUTE", `["k8s.pod.name=pod", "k8s.namespace.name=ns"]`)
+		t.Setenv("DT_BOOTSTRAPPER_ATTRIBUTE_CONTAINER", `[{"k8s.container.name":"app"}, "{\"k8s.container.name\":\"sidecar\"}"]`)
+		t.Setenv("DT_BOOTSTRAPPER_INTERVAL", "1m")
+		t.Setenv("DT_BOOTSTRAPPER_DEBUG", "true")
+
+		var values testFlags
+
+		cmd := newTestEnvCmd(t, &values)
+		cmd.SetArgs([]string{})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, "/target", values.target)
+		assert.Equal(t, []string{"k8s.pod.name=pod", "k8s.namespace.name=ns"}, values.attributes)
+		assert.Equal(t, []string{`{"k8s.container.name":"app"}`, `{"k8s.container.name":"sidecar"}`}, values.containers)
+		assert.Equal(t, time.Minute, values.interval)
+		assert.True(t, values.debug)
+	})
+
+	t.Run("a single item is taken as is", func(t *testing.T) {
+		t.Setenv("DT_BOOTSTRAPPER_TARGET", "/target")
+		t.Setenv("DT_BOOTSTRAPPER_ATTRIBUTE_CONTAINER", `{"k8s.container.name":"app","container_image.tags":"a,b"}`)
+
+		var values testFlags
+
+		cmd := newTestEnvCmd(t, &values)
+		cmd.SetArgs([]string{})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, []string{`{"k8s.container.name":"app","container_image.tags":"a,b"}`}, values.containers)
+	})
+
+	t.Run("precedence is flag > env > config file > default", func(t *testing.T) {
+		configFile := writeConfigFile(t, "config.yaml", "target: /file\nattribute: [k8s.pod.name=file]\ninterval: 1h\n")
+
+		t.Setenv("DT_BOOTSTRAPPER_CONFIG", configFile)
+		t.Setenv("DT_BOOTSTRAPPER_TARGET", "/env")
+		t.Setenv("DT_BOOTSTRAPPER_ATTRIBUTE", "k8s.pod.name=env")
+
+		var values testFlags
+
+		cmd := newTestEnvCmd(t, &values)
+		cmd.SetArgs([]string{"--target", "/explicit"})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, "/explicit", values.target)
+		assert.Equal(t, []string{"k8s.pod.name=env"}, values.attributes)
+		assert.Equal(t, time.Hour, values.interval)
+		assert.False(t, values.debug)
+
+		logger, logs := tests.NewTestLogger()
+		LogSources(logger, cmd)
+
+		sources := map[string]string{}
+		for _, entry := range logs.FilterMessage("flag source") {
+			sources[entry.Fields["flag"]] = entry.Fields["source"]
+
+			assert.NotContains(t, entry.Fields, "value")
+		}
+
+		assert.Equal(t, SourceFlag, sources["target"])
+		assert.Equal(t, SourceEnv, sources["config"])
+		assert.Equal(t, SourceEnv, sources["attribute"])
+		assert.Equal(t, SourceConfigFile, sources["interval"])
+		assert.Equal(t, SourceDefault, sources["debug"])
+		assert.NotContains(t, sources, "help")
+	})
+
+	t.Run("empty environment variables are ignored", func(t *testing.T) {
+		t.Setenv("DT_BOOTSTRAPPER_TARGET", "/target")
+		t.Setenv("DT_BOOTSTRAPPER_INTERVAL", "")
+
+		var values testFlags
+
+		cmd := newTestEnvCmd(t, &values)
+		cmd.SetArgs([]string{})
+		require.NoError(t, cmd.Execute())
+
+		assert.Equal(t, time.Second, values.interval)
+	})
+
+	t.Run("invalid environment variables are reported at once", func(t *testing.T) {
+		t.Setenv("DT_BOOTSTRAPPER_TARGET", "/target")
+		t.Setenv("DT_BOOTSTRAPPER_ATTRIBUTE", `["k8s.pod.name=pod"`)
+		t.Setenv("DT_BOOTSTRAPPER_ATTRIBUTE_CONTAINER", `[1]`)
+		t.Setenv("DT_BOOTSTRAPPER_INTERVAL", "soon")
+		t.Setenv("DT_BOOTSTRAPPER_DEBUG", "yes")
+
+		var values testFlags
+
+		cmd := newTestEnvCmd(t, &values)
+		cmd.SetArgs([]string{})
+		cmd.SilenceUsage = true
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "DT_BOOTSTRAPPER_ATTRIBUTE: must be a JSON list")
+		assert.ErrorContains(t, err, "DT_BOOTSTRAPPER_ATTRIBUTE_CONTAINER: attribute-container[0]: must be an object or a JSON string")
+		assert.ErrorContains(t, err, "DT_BOOTSTRAPPER_INTERVAL: ")
+		assert.ErrorContains(t, err, "DT_BOOTSTRAPPER_DEBUG: ")
+	})
+}
@@ -0,0 +1,53 @@
+package flags
+
+import (
+	"slices"
+
+	"github.com/spf13/pflag"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns the JSON schema of the config file for the given flags, as validated by ApplyConfigFile.
+func Schema(title string, flagSet *pflag.FlagSet) map[string]any {
+	properties := map[string]any{}
+
+	flagSet.VisitAll(func(flag *pflag.Flag) {
+		if slices.Contains(ignoredFlags, flag.Name) {
+			return
+		}
+
+		property := getPropertySchema(flag)
+		property["description"] = flag.Usage
+
+		properties[flag.Name] = property
+	})
+
+	return map[string]any{
+		"$schema":              schemaDraft,
+		"title":                title,
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func getPropertySchema(flag *pflag.Flag) map[string]any {
+	switch flag.Value.Type() {
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "int", "int64", "uint", "uint64":
+		return map[string]any{"type": "integer"}
+	case "duration":
+		return map[string]any{"type": "string", "pattern": `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`}
+	case "stringArray", "stringSlice":
+		items := map[string]any{"type": "string"}
+		if isJSONFlag(flag) {
+			items = map[string]any{"type": []string{"object", "string"}}
+		}
+
+		return map[string]any{"type": "array", "items": items}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -9,7 +10,12 @@ import (
 	"github.com/pkg/errors"
 )
 
-func CopyFolder(log logr.Logger, from string, to string) error {
+// copyChunkSize is the amount of data copied between two checks of the context.
+const copyChunkSize = 64 << 20
+
+// CopyFolder recursively copies the content of a folder.
+// The copy is aborted (leaving a partial copy behind) as soon as the context is cancelled.
+func CopyFolder(ctx context.Context, log logr.Logger, from string, to string) error {
 	fromInfo, err := os.Stat(from)
 	if err != nil {
 		return errors.WithStack(err)
@@ -30,20 +36,24 @@ func CopyFolder(log logr.Logger, from string, to string) error {
 	}
 
 	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return errors.WithStack(err)
+		}
+
 		fromPath := filepath.Join(from, entry.Name())
 		toPath := filepath.Join(to, entry.Name())
 
 		if entry.IsDir() {
 			log.V(1).Info("copying directory", "from", fromPath, "to", toPath)
 
-			err = CopyFolder(log, fromPath, toPath)
+			err = CopyFolder(ctx, log, fromPath, toPath)
 			if err != nil {
 				return err
 			}
 		} else {
 			log.V(1).Info("copying file", "from", fromPath, "to", toPath)
 
-			err = CopyFile(fromPath, toPath)
+			err = CopyFile(ctx, fromPath, toPath)
 			if err != nil {
 				return err
 			}
@@ -53,7 +63,9 @@ func CopyFolder(log logr.Logger, from string, to string) error {
 	return nil
 }
 
-func CopyFile(sourcePath string, destinationPath string) error {
+// CopyFile copies a single file, keeping its mode.
+// The copy is aborted (leaving a partial file behind) as soon as the context is cancelled.
+func CopyFile(ctx context.Context, sourcePath string, destinationPath string) error {
 	sourceFile, err := os.Open(sourcePath)
 	if err != nil {
 		return errors.WithStack(err)
@@ -73,7 +85,7 @@ func CopyFile(sourcePath string, destinationPath string) error {
 
 	defer func() { _ = destinationFile.Close() }()
 
-	_, err = io.Copy(destinationFile, sourceFile)
+	err = copyChunks(ctx, destinationFile, sourceFile)
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -85,3 +97,22 @@ func CopyFile(sourcePath string, destinationPath string) error {
 
 	return nil
 }
+
+// copyChunks copies the file in chunks and checks the context between them, so copying large files can be interrupted.
+// The chunks are copied between the *os.File values directly, so the kernel can copy them (copy_file_range, sendfile).
+func copyChunks(ctx context.Context, destination *os.File, source *os.File) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		_, err := io.CopyN(destination, source, copyChunkSize)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
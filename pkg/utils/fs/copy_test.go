@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -33,7 +34,7 @@ func TestCopyFolder(t *testing.T) {
 	err = os.MkdirAll(dst, 0755)
 	require.NoError(t, err)
 
-	err = CopyFolder(testLog, src, dst)
+	err = CopyFolder(t.Context(), testLog, src, dst)
 	require.NoError(t, err)
 
 	srcFiles, err := os.ReadDir(src)
@@ -45,6 +46,25 @@ func TestCopyFolder(t *testing.T) {
 	checkFolder(t, src, dst)
 }
 
+func TestCopyFolderCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	src := filepath.Join(tmpDir, "src")
+	err := os.MkdirAll(src, 0755)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(src, "file1.txt"), []byte("Hello"), 0600)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	dst := filepath.Join(tmpDir, "dst")
+	err = CopyFolder(ctx, testLog, src, dst)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.NoFileExists(t, filepath.Join(dst, "file1.txt"))
+}
+
 func TestCopyFile(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -60,7 +80,7 @@ func TestCopyFile(t *testing.T) {
 	err = os.MkdirAll(target, 0755)
 	require.NoError(t, err)
 
-	err = CopyFile(filepath.Join(source, "file1.txt"), filepath.Join(target, "file1.txt"))
+	err = CopyFile(t.Context(), filepath.Join(source, "file1.txt"), filepath.Join(target, "file1.txt"))
 	require.NoError(t, err)
 
 	sourceContent, err := os.ReadFile(filepath.Join(source, "file1.txt"))
@@ -118,3 +138,22 @@ func checkFolder(t *testing.T, src, dst string) {
 		}
 	}
 }
+
+func TestCopyFileCancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	source := filepath.Join(tmpDir, "file1.txt")
+	err := os.WriteFile(source, []byte("some content"), 0600)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	target := filepath.Join(tmpDir, "file2.txt")
+	err = CopyFile(ctx, source, target)
+	require.ErrorIs(t, err, context.Canceled)
+
+	targetContent, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Empty(t, targetContent)
+}
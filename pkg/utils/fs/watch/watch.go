@@ -0,0 +1,26 @@
+package watch
+
+import "errors"
+
+// ErrUnsupported is returned by New if file system events are not supported on the current platform.
+var ErrUnsupported = errors.New("file system events are not supported on this platform")
+
+const eventBufferSize = 16
+
+// Watcher reports the names of the entries changed (created, removed, renamed or modified) in a single directory.
+// It is only a hint that something changed, the receiver has to check the actual state itself.
+// Events for changes done by other hosts are not reported on most network file systems (e.g., NFS, SMB).
+type Watcher struct {
+	events chan string
+	closer func() error
+}
+
+// Events returns the channel with the names of the changed entries. It is closed when the Watcher is closed.
+func (w *Watcher) Events() <-chan string {
+	return w.events
+}
+
+// Close stops watching the directory.
+func (w *Watcher) Close() error {
+	return w.closer()
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+package watch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	watchMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_TO | unix.IN_MOVED_FROM | unix.IN_CLOSE_WRITE | unix.IN_ATTRIB
+
+	// offset of the len field in the inotify_event struct: wd(int32), mask(uint32), cookie(uint32), len(uint32)
+	nameLenOffset = 12
+
+	// the buffer fits at least a single event with the longest possible name
+	readBufferSize = 16 * (unix.SizeofInotifyEvent + unix.NAME_MAX + 1)
+)
+
+// New starts watching the given directory using inotify.
+// The directory must exist, it is not watched recursively.
+func New(dir string) (*Watcher, error) {
+	// the non-blocking fd is registered in the Go runtime poller by os.NewFile, so Close unblocks the pending Read
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+
+	if _, err := unix.InotifyAddWatch(fd, dir, watchMask); err != nil {
+		_ = unix.Close(fd)
+
+		return nil, fmt.Errorf("failed to watch %s: %w", dir, err)
+	}
+
+	file := os.NewFile(uintptr(fd), "inotify")
+	events := make(chan string, eventBufferSize)
+
+	go readEvents(file, events)
+
+	return &Watcher{events: events, closer: file.Close}, nil
+}
+
+func readEvents(file *os.File, events chan<- string) {
+	defer close(events)
+
+	buf := make([]byte, readBufferSize)
+
+	for {
+		n, err := file.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for _, name := range parseEvents(buf[:n]) {
+			select {
+			case events <- name:
+			default:
+				// the receiver is busy, it will check the state anyway, so dropping the event is fine
+			}
+		}
+	}
+}
+
+// parseEvents decodes the raw inotify_event structs and returns the names of the changed entries.
+func parseEvents(buf []byte) []string {
+	var names []string
+
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		nameLen := int(binary.NativeEndian.Uint32(buf[offset+nameLenOffset:]))
+		nameStart := offset + unix.SizeofInotifyEvent
+		nameEnd := min(nameStart+nameLen, len(buf))
+
+		names = append(names, strings.TrimRight(string(buf[nameStart:nameEnd]), "\x00"))
+
+		offset = nameEnd
+	}
+
+	return names
+}
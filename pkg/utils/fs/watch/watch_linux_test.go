@@ -0,0 +1,78 @@
+//go:build linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Run("reports the name of a created symlink", func(t *testing.T) {
+		dir := t.TempDir()
+
+		watcher, err := New(dir)
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, watcher.Close()) }()
+
+		require.NoError(t, os.Symlink("1.2.3", filepath.Join(dir, "active")))
+
+		requireEvent(t, watcher, "active")
+	})
+
+	t.Run("reports the name of an entry renamed into the directory", func(t *testing.T) {
+		dir := t.TempDir()
+		otherDir := t.TempDir()
+
+		watcher, err := New(dir)
+		require.NoError(t, err)
+
+		defer func() { require.NoError(t, watcher.Close()) }()
+
+		tmpPath := filepath.Join(otherDir, "tmp")
+		require.NoError(t, os.Symlink("1.2.3", tmpPath))
+		require.NoError(t, os.Rename(tmpPath, filepath.Join(dir, "active")))
+
+		requireEvent(t, watcher, "active")
+	})
+
+	t.Run("events channel is closed after Close", func(t *testing.T) {
+		watcher, err := New(t.TempDir())
+		require.NoError(t, err)
+		require.NoError(t, watcher.Close())
+
+		select {
+		case _, ok := <-watcher.Events():
+			require.False(t, ok)
+		case <-time.After(5 * time.Second):
+			t.Fatal("events channel was not closed")
+		}
+	})
+
+	t.Run("fails for a missing directory", func(t *testing.T) {
+		_, err := New(filepath.Join(t.TempDir(), "missing"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func requireEvent(t *testing.T, watcher *Watcher, expectedName string) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case name := <-watcher.Events():
+			if name == expectedName {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s received", expectedName)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package watch
+
+// New is not supported on this platform, use polling instead.
+func New(_ string) (*Watcher, error) {
+	return nil, ErrUnsupported
+}
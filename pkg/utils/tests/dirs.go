@@ -4,8 +4,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
+	"github.com/Dynatrace/dynatrace-bootstrapper/pkg/deployment/marker"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,6 +15,7 @@ const (
 	dirPerm755  fs.FileMode = 0o755
 	dirPerm700  fs.FileMode = 0o700
 	filePerm600 fs.FileMode = 0o600
+	filePerm644 fs.FileMode = 0o644
 )
 
 // SetupSourceDirectory creates a mock source directory for testing purposes
@@ -44,6 +47,8 @@ func SetupTargetDirectory(t *testing.T, targetBaseDir, agentVersionDir, activeLi
 	oneAgentDirPath := filepath.Join(targetBaseDir, "oneagent", agentVersionDir)
 	err := os.MkdirAll(oneAgentDirPath, dirPerm755)
 	require.NoError(t, err)
+	// the folder is deployed with all technologies for the architecture of this host
+	require.NoError(t, marker.Write(oneAgentDirPath, marker.Selection{Arch: runtime.GOARCH}))
 
 	if activeLinkAgentVersion != "" {
 		activeLinkPath := filepath.Join(targetBaseDir, "oneagent/active")
@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateGoldenFilesEnv is the environment variable to set to "true" to update the golden files instead of comparing with them.
+const UpdateGoldenFilesEnv = "UPDATE_GOLDEN_FILES"
+
+// RequireGoldenJSON requires the golden file at the given path to contain the indented JSON of the given value.
+func RequireGoldenJSON(t *testing.T, path string, value any) {
+	t.Helper()
+
+	actual, err := json.MarshalIndent(value, "", "  ")
+	require.NoError(t, err)
+
+	actual = append(actual, '\n')
+
+	if os.Getenv(UpdateGoldenFilesEnv) == "true" {
+		require.NoError(t, os.WriteFile(path, actual, filePerm644))
+
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual), "%s is outdated, run the tests with %s=true to update it", path, UpdateGoldenFilesEnv)
+}